@@ -0,0 +1,229 @@
+package batch
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Result describes the outcome of processing a single input file
+type Result struct {
+	Input     string
+	OutputDir string
+	Files     []string
+	Abilities int
+	Warnings  []string
+	// Output holds the formatted text when it is printed rather than written to files
+	Output string
+	Err    error
+}
+
+// ProcessFunc processes one input file and reports its result
+type ProcessFunc func(input string) Result
+
+// Summary aggregates the results of a batch run
+type Summary struct {
+	Files     int
+	Succeeded int
+	Failed    int
+	Abilities int
+	Warnings  int
+}
+
+// ExpandInputs resolves files, directories and glob patterns into a sorted,
+// de-duplicated list of markdown files. Directories are walked recursively
+// and only .md files inside them are included; explicitly named files are
+// always included regardless of extension. Files from directories and globs
+// whose names end in one of skip, such as the ".homebrewery.md" files the
+// tool writes itself, are left out so a second run doesn't read its output.
+func ExpandInputs(inputs []string, skip ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	generated := func(path string) bool {
+		for _, suffix := range skip {
+			if strings.HasSuffix(strings.ToLower(path), suffix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, input := range inputs {
+		matches := []string{input}
+		if hasGlobMeta(input) {
+			globbed, err := filepath.Glob(input)
+			if err != nil {
+				return nil, fmt.Errorf("invalid glob pattern %q: %w", input, err)
+			}
+			if len(globbed) == 0 {
+				return nil, fmt.Errorf("no files match %q", input)
+			}
+			matches = globbed
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read input %q: %w", match, err)
+			}
+
+			if !info.IsDir() {
+				if match == input || !generated(match) {
+					add(match)
+				}
+				continue
+			}
+
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && IsMarkdown(path) && !generated(path) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk directory %q: %w", match, err)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// WithoutOutputs drops files that lie inside another file's output
+// directory. In a batch each input writes to a directory of its own, so
+// markdown found there was written by an earlier run, not by the author.
+func WithoutOutputs(files []string, dirs map[string]string) []string {
+	var kept []string
+	for _, file := range files {
+		inside := false
+		for input, dir := range dirs {
+			if input != file && isInside(file, dir) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+// isInside reports whether path lies inside dir
+func isInside(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// OutputDirs assigns an output directory to each input file. A single input
+// writes straight into baseDir (or next to the input in vault mode); several
+// inputs each get a subdirectory named after the file so their outputs don't
+// overwrite each other.
+func OutputDirs(inputs []string, baseDir string, vaultMode bool) (map[string]string, error) {
+	dirs := make(map[string]string, len(inputs))
+	owners := make(map[string]string, len(inputs))
+
+	for _, input := range inputs {
+		dir := baseDir
+		if vaultMode {
+			dir = filepath.Dir(input)
+		}
+		if len(inputs) > 1 {
+			dir = filepath.Join(dir, Stem(input))
+		}
+
+		if owner, ok := owners[dir]; ok {
+			return nil, fmt.Errorf("%s and %s would both write to %s", owner, input, dir)
+		}
+		owners[dir] = input
+		dirs[input] = dir
+	}
+
+	return dirs, nil
+}
+
+// Run processes inputs concurrently using a fixed pool of workers.
+// Results are returned in the same order as inputs.
+func Run(inputs []string, workers int, process ProcessFunc) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	results := make([]Result, len(inputs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = process(inputs[i])
+				results[i].Input = inputs[i]
+			}
+		}()
+	}
+
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// Summarize aggregates per-file results into totals
+func Summarize(results []Result) Summary {
+	summary := Summary{Files: len(results)}
+	for _, result := range results {
+		if result.Err != nil {
+			summary.Failed++
+			continue
+		}
+		summary.Succeeded++
+		summary.Abilities += result.Abilities
+		summary.Warnings += len(result.Warnings)
+	}
+	return summary
+}
+
+// Stem returns the file name without directory or extension
+func Stem(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// hasGlobMeta reports whether the input contains glob metacharacters
+func hasGlobMeta(input string) bool {
+	return strings.ContainsAny(input, "*?[")
+}
+
+// IsMarkdown reports whether the path has a markdown file extension
+func IsMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,210 @@
+package batch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// writeFiles creates empty files (and parent directories) under dir
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("## Traits\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExpandInputs_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "fighter.md", "wizard.md", "notes.txt", "monsters/goblin.md")
+
+	files, err := ExpandInputs([]string{dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "fighter.md"),
+		filepath.Join(dir, "monsters", "goblin.md"),
+		filepath.Join(dir, "wizard.md"),
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+}
+
+func TestExpandInputs_GlobAndDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "fighter.md", "wizard.md", "rogue.txt")
+
+	files, err := ExpandInputs([]string{
+		filepath.Join(dir, "*.md"),
+		filepath.Join(dir, "fighter.md"),
+		filepath.Join(dir, "rogue.txt"),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "fighter.md"),
+		filepath.Join(dir, "rogue.txt"),
+		filepath.Join(dir, "wizard.md"),
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+}
+
+func TestExpandInputs_SkipsGenerated(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "fighter.md", "fighter.homebrewery.md", "wizard/wizard.Homebrewery.md")
+
+	files, err := ExpandInputs([]string{dir, filepath.Join(dir, "*.md")}, ".homebrewery.md")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{filepath.Join(dir, "fighter.md")}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	// A generated file named explicitly is still read
+	named := filepath.Join(dir, "fighter.homebrewery.md")
+	files, err = ExpandInputs([]string{named}, ".homebrewery.md")
+	if err != nil || !reflect.DeepEqual(files, []string{named}) {
+		t.Errorf("Expected %v, got %v (%v)", []string{named}, files, err)
+	}
+}
+
+func TestWithoutOutputs(t *testing.T) {
+	files := []string{"chars/fighter.md", "chars/fighter/fighter.homebrewery.md", "chars/fighterly.md", "chars/wizard.md"}
+	dirs := map[string]string{
+		"chars/fighter.md":                     "chars/fighter",
+		"chars/fighter/fighter.homebrewery.md": "chars/fighter.homebrewery",
+		"chars/fighterly.md":                   "chars/fighterly",
+		"chars/wizard.md":                      "chars/wizard",
+	}
+
+	expected := []string{"chars/fighter.md", "chars/fighterly.md", "chars/wizard.md"}
+	if kept := WithoutOutputs(files, dirs); !reflect.DeepEqual(kept, expected) {
+		t.Errorf("Expected %v, got %v", expected, kept)
+	}
+}
+
+func TestExpandInputs_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []string{
+		filepath.Join(dir, "missing.md"),
+		filepath.Join(dir, "*.md"),
+	}
+
+	for _, input := range tests {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			if _, err := ExpandInputs([]string{input}); err == nil {
+				t.Errorf("Expected error for %s, got nil", input)
+			}
+		})
+	}
+}
+
+func TestOutputDirs_Single(t *testing.T) {
+	dirs, err := OutputDirs([]string{"chars/fighter.md"}, "out", false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dirs["chars/fighter.md"] != "out" {
+		t.Errorf("Expected out, got %s", dirs["chars/fighter.md"])
+	}
+
+	dirs, err = OutputDirs([]string{"chars/fighter.md"}, "out", true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dirs["chars/fighter.md"] != "chars" {
+		t.Errorf("Expected chars, got %s", dirs["chars/fighter.md"])
+	}
+}
+
+func TestOutputDirs_Multiple(t *testing.T) {
+	inputs := []string{"chars/fighter.md", "chars/wizard.md"}
+
+	dirs, err := OutputDirs(inputs, "out", false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"chars/fighter.md": filepath.Join("out", "fighter"),
+		"chars/wizard.md":  filepath.Join("out", "wizard"),
+	}
+	if !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("Expected %v, got %v", expected, dirs)
+	}
+}
+
+func TestOutputDirs_Collision(t *testing.T) {
+	_, err := OutputDirs([]string{"party/goblin.md", "monsters/goblin.md"}, "out", false)
+	if err == nil {
+		t.Fatal("Expected collision error, got nil")
+	}
+	if !strings.Contains(err.Error(), "goblin") {
+		t.Errorf("Expected error to mention goblin, got %v", err)
+	}
+}
+
+func TestRun_PreservesOrderAndUsesWorkers(t *testing.T) {
+	inputs := []string{"a.md", "b.md", "c.md", "d.md", "e.md"}
+	var calls atomic.Int32
+
+	results := Run(inputs, 3, func(input string) Result {
+		calls.Add(1)
+		if input == "c.md" {
+			return Result{Err: errors.New("boom")}
+		}
+		return Result{Abilities: 2, Warnings: []string{"w"}}
+	})
+
+	if int(calls.Load()) != len(inputs) {
+		t.Errorf("Expected %d calls, got %d", len(inputs), calls.Load())
+	}
+
+	for i, result := range results {
+		if result.Input != inputs[i] {
+			t.Errorf("Expected result %d for %s, got %s", i, inputs[i], result.Input)
+		}
+	}
+
+	summary := Summarize(results)
+	expected := Summary{Files: 5, Succeeded: 4, Failed: 1, Abilities: 8, Warnings: 4}
+	if summary != expected {
+		t.Errorf("Expected %+v, got %+v", expected, summary)
+	}
+}
+
+func TestRun_Empty(t *testing.T) {
+	results := Run(nil, 4, func(input string) Result {
+		t.Errorf("Unexpected call for %s", input)
+		return Result{}
+	})
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
+
+func TestStem(t *testing.T) {
+	if got := Stem("party/Fighter.v2.md"); got != "Fighter.v2" {
+		t.Errorf("Expected Fighter.v2, got %s", got)
+	}
+}
@@ -0,0 +1,203 @@
+package clipboard
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Backend copies text to a clipboard
+type Backend interface {
+	Name() string
+	Copy(text string) error
+}
+
+// Runner executes an external command with text on its standard input
+type Runner func(name string, args []string, stdin string) error
+
+// Environment describes the system a backend is detected for. It exists so
+// detection and command execution can be swapped out in tests.
+type Environment struct {
+	GOOS     string
+	Getenv   func(string) string
+	LookPath func(string) (string, error)
+	Run      Runner
+	// Terminal receives OSC 52 escape sequences
+	Terminal io.Writer
+}
+
+// DefaultEnvironment returns the environment of the running process
+func DefaultEnvironment() Environment {
+	return Environment{
+		GOOS:     runtime.GOOS,
+		Getenv:   os.Getenv,
+		LookPath: exec.LookPath,
+		Run:      execRunner,
+		Terminal: os.Stderr,
+	}
+}
+
+// Backends lists the names accepted by ByName, in detection order
+var Backends = []string{"pbcopy", "wl-copy", "xclip", "xsel", "clip.exe", "osc52"}
+
+// ErrNoBackend is returned when no clipboard backend is usable
+var ErrNoBackend = errors.New("no clipboard backend found (install wl-clipboard, xclip or xsel, or use --backend osc52)")
+
+// Detect picks the most suitable backend for the environment
+func Detect(env Environment) (Backend, error) {
+	available := func(command string) bool {
+		_, err := env.LookPath(command)
+		return err == nil
+	}
+
+	switch env.GOOS {
+	case "darwin":
+		if available("pbcopy") {
+			return commandBackend("pbcopy", env), nil
+		}
+	case "windows":
+		return commandBackend("clip.exe", env), nil
+	default:
+		if env.Getenv("WAYLAND_DISPLAY") != "" && available("wl-copy") {
+			return commandBackend("wl-copy", env), nil
+		}
+		if env.Getenv("DISPLAY") != "" {
+			if available("xclip") {
+				return commandBackend("xclip", env), nil
+			}
+			if available("xsel") {
+				return commandBackend("xsel", env), nil
+			}
+		}
+		// WSL exposes the Windows clipboard through clip.exe
+		if available("clip.exe") {
+			return commandBackend("clip.exe", env), nil
+		}
+	}
+
+	// Over SSH the local terminal can still set the clipboard
+	if env.Getenv("SSH_TTY") != "" || env.Getenv("SSH_CONNECTION") != "" {
+		return &OSC52{w: env.Terminal}, nil
+	}
+
+	return nil, ErrNoBackend
+}
+
+// ByName returns the named backend without checking that it is installed
+func ByName(name string, env Environment) (Backend, error) {
+	switch name {
+	case "osc52":
+		return &OSC52{w: env.Terminal}, nil
+	case "pbcopy", "wl-copy", "xclip", "xsel", "clip.exe":
+		return commandBackend(name, env), nil
+	default:
+		return nil, fmt.Errorf("unknown clipboard backend %q (must be one of %s)", name, strings.Join(Backends, ", "))
+	}
+}
+
+// Command copies text by piping it into an external program
+type Command struct {
+	name string
+	args []string
+	run  Runner
+}
+
+// commandBackend builds the Command backend for a known clipboard program
+func commandBackend(name string, env Environment) *Command {
+	var args []string
+	switch name {
+	case "xclip":
+		args = []string{"-selection", "clipboard"}
+	case "xsel":
+		args = []string{"--clipboard", "--input"}
+	}
+	return &Command{name: name, args: args, run: env.Run}
+}
+
+// Name returns the program name
+func (c *Command) Name() string {
+	return c.name
+}
+
+// Copy runs the program with text on its standard input
+func (c *Command) Copy(text string) error {
+	return c.run(c.name, c.args, text)
+}
+
+// OSC52 copies text by writing the OSC 52 escape sequence to the terminal,
+// which most modern terminal emulators turn into a clipboard write. It works
+// over SSH but depends on the terminal allowing clipboard access.
+type OSC52 struct {
+	w io.Writer
+}
+
+// Name returns "osc52"
+func (o *OSC52) Name() string {
+	return "osc52"
+}
+
+// Copy writes the escape sequence carrying the base64-encoded text
+func (o *OSC52) Copy(text string) error {
+	encoded := base64.StdEncoding.EncodeToString([]byte(text))
+	_, err := fmt.Fprintf(o.w, "\x1b]52;c;%s\x07", encoded)
+	return err
+}
+
+// Item is one piece of text to copy, such as a formatted section
+type Item struct {
+	Label string
+	Text  string
+}
+
+// CopySequence copies items one at a time. After each copy except the last it
+// waits for Enter on in, so the user can paste the item before the next one
+// replaces it. Progress messages are written to out.
+func CopySequence(backend Backend, items []Item, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+
+	for i, item := range items {
+		if err := backend.Copy(item.Text); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", item.Label, err)
+		}
+
+		if i == len(items)-1 {
+			fmt.Fprintf(out, "✓ Copied %s\n", item.Label)
+			break
+		}
+
+		fmt.Fprintf(out, "✓ Copied %s - paste it, then press Enter for %s", item.Label, items[i+1].Label)
+		if _, err := reader.ReadString('\n'); err != nil {
+			fmt.Fprintln(out)
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("input closed before %s was copied", items[i+1].Label)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execRunner runs the command for real, capturing stderr for error messages
+func execRunner(name string, args []string, stdin string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s failed: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
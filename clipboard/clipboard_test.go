@@ -0,0 +1,204 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeBackend records copied text instead of touching a real clipboard
+type fakeBackend struct {
+	copied []string
+	err    error
+}
+
+func (f *fakeBackend) Name() string {
+	return "fake"
+}
+
+func (f *fakeBackend) Copy(text string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.copied = append(f.copied, text)
+	return nil
+}
+
+// fakeEnvironment builds an environment with the given variables and installed commands
+func fakeEnvironment(goos string, vars map[string]string, installed ...string) Environment {
+	return Environment{
+		GOOS:   goos,
+		Getenv: func(key string) string { return vars[key] },
+		LookPath: func(command string) (string, error) {
+			for _, name := range installed {
+				if name == command {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		},
+		Run:      func(name string, args []string, stdin string) error { return nil },
+		Terminal: &bytes.Buffer{},
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		vars      map[string]string
+		installed []string
+		expected  string
+	}{
+		{"macOS", "darwin", nil, []string{"pbcopy"}, "pbcopy"},
+		{"Windows", "windows", nil, nil, "clip.exe"},
+		{"Wayland", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, []string{"wl-copy", "xclip"}, "wl-copy"},
+		{"X11 xclip", "linux", map[string]string{"DISPLAY": ":0"}, []string{"xclip", "xsel"}, "xclip"},
+		{"X11 xsel", "linux", map[string]string{"DISPLAY": ":0"}, []string{"xsel"}, "xsel"},
+		{"Wayland without wl-copy", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, []string{"xsel"}, "xsel"},
+		{"WSL", "linux", nil, []string{"clip.exe"}, "clip.exe"},
+		{"SSH", "linux", map[string]string{"SSH_TTY": "/dev/pts/0"}, nil, "osc52"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := Detect(fakeEnvironment(tt.goos, tt.vars, tt.installed...))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if backend.Name() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, backend.Name())
+			}
+		})
+	}
+}
+
+func TestDetect_NoBackend(t *testing.T) {
+	_, err := Detect(fakeEnvironment("linux", nil))
+	if !errors.Is(err, ErrNoBackend) {
+		t.Errorf("Expected ErrNoBackend, got %v", err)
+	}
+}
+
+func TestByName_CommandArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"pbcopy", nil},
+		{"wl-copy", nil},
+		{"xclip", []string{"-selection", "clipboard"}},
+		{"xsel", []string{"--clipboard", "--input"}},
+		{"clip.exe", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotName, gotStdin string
+			var gotArgs []string
+
+			env := fakeEnvironment("linux", nil)
+			env.Run = func(name string, args []string, stdin string) error {
+				gotName, gotArgs, gotStdin = name, args, stdin
+				return nil
+			}
+
+			backend, err := ByName(tt.name, env)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := backend.Copy("Darkvision."); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if gotName != tt.name {
+				t.Errorf("Expected command %s, got %s", tt.name, gotName)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.expected) {
+				t.Errorf("Expected args %v, got %v", tt.expected, gotArgs)
+			}
+			if gotStdin != "Darkvision." {
+				t.Errorf("Expected stdin 'Darkvision.', got %q", gotStdin)
+			}
+		})
+	}
+}
+
+func TestByName_Unknown(t *testing.T) {
+	if _, err := ByName("carrier-pigeon", fakeEnvironment("linux", nil)); err == nil {
+		t.Error("Expected error for unknown backend, got nil")
+	}
+}
+
+func TestOSC52_Copy(t *testing.T) {
+	var terminal bytes.Buffer
+	env := fakeEnvironment("linux", nil)
+	env.Terminal = &terminal
+
+	backend, err := ByName("osc52", env)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := backend.Copy("hello"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "\x1b]52;c;aGVsbG8=\x07"
+	if terminal.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, terminal.String())
+	}
+}
+
+func TestCopySequence_WaitsBetweenItems(t *testing.T) {
+	backend := &fakeBackend{}
+	items := []Item{
+		{Label: "Traits", Text: "Darkvision."},
+		{Label: "Actions", Text: "Longsword."},
+		{Label: "Reactions", Text: "Parry."},
+	}
+	var out bytes.Buffer
+
+	err := CopySequence(backend, items, strings.NewReader("\n\n"), &out)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"Darkvision.", "Longsword.", "Parry."}
+	if !reflect.DeepEqual(backend.copied, expected) {
+		t.Errorf("Expected %v, got %v", expected, backend.copied)
+	}
+	if !strings.Contains(out.String(), "press Enter for Actions") {
+		t.Errorf("Expected prompt for Actions, got %q", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "✓ Copied Reactions\n") {
+		t.Errorf("Expected final confirmation, got %q", out.String())
+	}
+}
+
+func TestCopySequence_StopsWhenInputCloses(t *testing.T) {
+	backend := &fakeBackend{}
+	items := []Item{
+		{Label: "Traits", Text: "Darkvision."},
+		{Label: "Actions", Text: "Longsword."},
+	}
+
+	err := CopySequence(backend, items, strings.NewReader(""), &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("Expected error when input closes, got nil")
+	}
+	if len(backend.copied) != 1 {
+		t.Errorf("Expected only the first item copied, got %v", backend.copied)
+	}
+}
+
+func TestCopySequence_BackendError(t *testing.T) {
+	backend := &fakeBackend{err: errors.New("no display")}
+	items := []Item{{Label: "Traits", Text: "Darkvision."}}
+
+	err := CopySequence(backend, items, strings.NewReader(""), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "Traits") {
+		t.Errorf("Expected error mentioning Traits, got %v", err)
+	}
+}
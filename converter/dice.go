@@ -26,6 +26,7 @@ var (
 	modifierRegex     = regexp.MustCompile(`[+-]\d+`)
 	d20RollRegex      = regexp.MustCompile(`^\d*d20([+-]\d+)?$`)
 	averageRegex      = regexp.MustCompile(`^(\d+)d(\d+)([+-]\d+)?$`)
+	rechargeRegex     = regexp.MustCompile(`\bRecharge ([1-6])(?:[-–](\d+))?\b`)
 )
 
 // RollableData represents the JSON data embedded in rollable tags
@@ -117,6 +118,56 @@ func ConvertDiceRolls(text string, actionName string) (string, error) {
 	return result, nil
 }
 
+// ConvertRecharge converts "Recharge 5–6" and "Recharge 6" to D&D Beyond d6
+// recharge rolls, keeping the text as the display value. A range that doesn't
+// end at 6, such as "Recharge 4-5", is left as written.
+func ConvertRecharge(text string, actionName string) string {
+	return rechargeRegex.ReplaceAllStringFunc(text, func(match string) string {
+		if upper := rechargeRegex.FindStringSubmatch(match)[2]; upper != "" && upper != "6" {
+			return match
+		}
+		data := RollableData{DiceNotation: "1d6", RollType: "recharge", RollAction: actionName}
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return match
+		}
+		return fmt.Sprintf("[rollable]%s;%s[/rollable]", match, jsonData)
+	})
+}
+
+// ExtractRollables returns the rollable data for every valid keyword roll in
+// text, in the order they appear. Invalid dice notation is skipped, exactly as
+// ConvertDiceRolls leaves it unconverted.
+func ExtractRollables(text string, actionName string) []RollableData {
+	rollables := []RollableData{}
+
+	for _, match := range rollPatternRegex.FindAllStringSubmatch(text, -1) {
+		normalized, err := ParseDiceNotation(match[2])
+		if err != nil {
+			continue
+		}
+
+		rollables = append(rollables, RollableData{
+			DiceNotation: normalized,
+			RollType:     match[1],
+			RollAction:   actionName,
+		})
+	}
+
+	return rollables
+}
+
+// DisplayValue returns the text shown inside a rollable tag for normalized
+// dice notation: the modifier for d20 rolls, average and notation otherwise
+func DisplayValue(notation string) string {
+	return getDisplayValue(notation)
+}
+
+// Average returns the rounded average result of normalized dice notation
+func Average(notation string) int {
+	return calculateAverage(notation)
+}
+
 // extractModifier extracts the +X or -X modifier from dice notation
 func extractModifier(notation string) string {
 	return modifierRegex.FindString(notation)
@@ -16,6 +16,7 @@ func TestParseDiceNotation_Valid(t *testing.T) {
 		{"2d6-1", "2d6-1"},
 		{"d20", "1d20"}, // implicit 1
 		{"3d8+4", "3d8+4"},
+		{"200d6", "200d6"}, // only rolling is capped
 	}
 
 	for _, tt := range tests {
@@ -302,3 +303,55 @@ func TestConvertDiceRolls_VariousDiceTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractRollables(t *testing.T) {
+	text := "to hit: 1d20+5, reach 5 ft. Hit: damage: 2d6+3 slashing, or damage: 1d3 on a miss. healing: d8"
+
+	rollables := ExtractRollables(text, "Greatsword")
+
+	expected := []RollableData{
+		{DiceNotation: "1d20+5", RollType: "to hit", RollAction: "Greatsword"},
+		{DiceNotation: "2d6+3", RollType: "damage", RollAction: "Greatsword"},
+		{DiceNotation: "1d8", RollType: "healing", RollAction: "Greatsword"},
+	}
+
+	if len(rollables) != len(expected) {
+		t.Fatalf("Expected %d rollables, got %d: %v", len(expected), len(rollables), rollables)
+	}
+	for i := range expected {
+		if rollables[i] != expected[i] {
+			t.Errorf("Rollable %d: expected %+v, got %+v", i, expected[i], rollables[i])
+		}
+	}
+}
+
+func TestExtractRollables_NoRolls(t *testing.T) {
+	rollables := ExtractRollables("You can see in the dark. DC 13.", "Darkvision")
+	if len(rollables) != 0 {
+		t.Errorf("Expected no rollables, got %v", rollables)
+	}
+}
+
+func TestConvertRecharge(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"range", "Breath Weapon (Recharge 5–6).", `Breath Weapon ([rollable]Recharge 5–6;{"diceNotation":"1d6","rollType":"recharge","rollAction":"Breath Weapon"}[/rollable]).`},
+		{"hyphen", "(Recharge 4-6)", `([rollable]Recharge 4-6;{"diceNotation":"1d6","rollType":"recharge","rollAction":"Breath Weapon"}[/rollable])`},
+		{"six only", "(Recharge 6)", `([rollable]Recharge 6;{"diceNotation":"1d6","rollType":"recharge","rollAction":"Breath Weapon"}[/rollable])`},
+		{"not a recharge", "Recharges after a Long Rest. Recharge 7.", "Recharges after a Long Rest. Recharge 7."},
+		{"range not ending at 6", "(Recharge 4-5)", "(Recharge 4-5)"},
+		{"range past 6", "(Recharge 5–66)", "(Recharge 5–66)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ConvertRecharge(tt.input, "Breath Weapon")
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Pre-compiled patterns for rolls written without keywords, most specific first
+var (
+	inferToHitRegex    = regexp.MustCompile(`(?i)([+\-−]\s?\d+) to hit\b`)
+	inferAverageRegex  = regexp.MustCompile(`(?i)\b(\d+) ?\((\d*d\d+(?:\s*[+\-−]\s*\d+)?)\)((?: [a-z]+)? (?:damage|hit points)\b)`)
+	inferDiceRegex     = regexp.MustCompile(`(?i)\b(\d*d\d+(?:\s*[+\-−]\s*\d+)?)((?: [a-z]+)? (?:damage|hit points)\b)`)
+	inferDCRegex       = regexp.MustCompile(`\bDC ?\d+\b`)
+	inferBareDiceRegex = regexp.MustCompile(`(?i)\b\d*d\d+(?:\s*[+\-−]\s*\d+)?\b`)
+	keywordBeforeRegex = regexp.MustCompile(`(?:to hit|damage|healing|save):\s*$`)
+	regainBeforeRegex  = regexp.MustCompile(`(?i)\bregains?\s+$`)
+)
+
+// Confidence is how sure InferRolls is of the type of a roll
+type Confidence int
+
+const (
+	// LowConfidence rolls are reported but left as text
+	LowConfidence Confidence = iota + 1
+	// MediumConfidence rolls are converted, but have less context than a
+	// stat block gives, such as "2d6 fire damage" without its average
+	MediumConfidence
+	// HighConfidence rolls are written the way stat blocks write them, such
+	// as "+5 to hit" or "10 (3d6) fire damage"
+	HighConfidence
+)
+
+// String returns "low", "medium" or "high"
+func (c Confidence) String() string {
+	switch c {
+	case LowConfidence:
+		return "low"
+	case MediumConfidence:
+		return "medium"
+	case HighConfidence:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// Inference is a roll InferRolls found in text without a keyword
+type Inference struct {
+	// Text is the roll as written, such as "+5 to hit"
+	Text string
+	// RollType and DiceNotation are the keyword roll it was rewritten as;
+	// both are empty for low confidence rolls left as text
+	RollType     string
+	DiceNotation string
+	Confidence   Confidence
+	// Reason says why the confidence isn't high
+	Reason string
+}
+
+// String describes the inference, such as `read "+5 to hit" as to hit:
+// 1d20+5 (high confidence)`
+func (i Inference) String() string {
+	confidence := i.Confidence.String() + " confidence"
+	if i.Reason != "" {
+		confidence += ": " + i.Reason
+	}
+	if i.RollType == "" {
+		return fmt.Sprintf("left %q as text (%s)", i.Text, confidence)
+	}
+	return fmt.Sprintf("read %q as %s: %s (%s)", i.Text, i.RollType, i.DiceNotation, confidence)
+}
+
+// inferredRoll is one roll found by InferRolls, with where it was written and
+// the keyword text that replaces it. bare is set for dice with no roll type
+// around them.
+type inferredRoll struct {
+	start, end  int
+	replacement string
+	inference   Inference
+	bare        bool
+}
+
+// InferRolls rewrites rolls written without keywords as keyword rolls,
+// working out the roll type from the words around them: "+5 to hit" becomes
+// "to hit: 1d20+5", "takes 10 (3d6) fire damage" becomes "takes damage: 3d6
+// fire damage" and "regains 1d8+4 hit points" becomes "regains healing:
+// 1d8+4 hit points". Temporary hit points, save DCs and dice with no roll
+// type around them are left as text. It returns an inference for every roll
+// found, in order, except that dice with no roll type are noted once for each
+// reason, since prose is full of them.
+func InferRolls(text string) (string, []Inference) {
+	var rolls []inferredRoll
+	add := func(roll inferredRoll) {
+		if keywordBeforeRegex.MatchString(text[:roll.start]) {
+			return
+		}
+		for _, other := range rolls {
+			if roll.start < other.end && other.start < roll.end {
+				return
+			}
+		}
+		rolls = append(rolls, roll)
+	}
+
+	for _, m := range inferToHitRegex.FindAllStringSubmatchIndex(text, -1) {
+		keyword := ToHitKeyword(text[m[2]:m[3]])
+		notation := strings.TrimPrefix(keyword, "to hit: ")
+		add(inferredRoll{m[0], m[1], keyword, Inference{Text: text[m[0]:m[1]], RollType: "to hit", DiceNotation: notation, Confidence: HighConfidence}, false})
+	}
+
+	for _, m := range inferAverageRegex.FindAllStringSubmatchIndex(text, -1) {
+		notation, err := ParseDiceNotation(normalizeDice(text[m[4]:m[5]]))
+		if err != nil {
+			continue
+		}
+		inference := Inference{Text: text[m[0]:m[1]], RollType: rollTypeFor(text[m[6]:m[7]]), DiceNotation: notation, Confidence: HighConfidence}
+		if inference.RollType == "" {
+			add(temporaryHitPoints(m[0], m[1], text))
+			continue
+		}
+		if !averageMatches(text[m[2]:m[3]], notation) {
+			inference.Confidence = MediumConfidence
+			inference.Reason = "average " + text[m[2]:m[3]] + " doesn't match " + notation
+		}
+		add(inferredRoll{m[0], m[1], inference.RollType + ": " + notation + text[m[6]:m[7]], inference, false})
+	}
+
+	for _, m := range inferDiceRegex.FindAllStringSubmatchIndex(text, -1) {
+		notation, err := ParseDiceNotation(normalizeDice(text[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		inference := Inference{Text: text[m[0]:m[1]], RollType: rollTypeFor(text[m[4]:m[5]]), DiceNotation: notation, Confidence: MediumConfidence, Reason: "no average given"}
+		if inference.RollType == "" {
+			add(temporaryHitPoints(m[0], m[1], text))
+			continue
+		}
+		if inference.RollType == "healing" && regainBeforeRegex.MatchString(text[:m[0]]) {
+			inference.Confidence, inference.Reason = HighConfidence, ""
+		}
+		add(inferredRoll{m[0], m[1], inference.RollType + ": " + notation + text[m[4]:m[5]], inference, false})
+	}
+
+	for _, m := range inferDCRegex.FindAllStringIndex(text, -1) {
+		match := text[m[0]:m[1]]
+		add(inferredRoll{m[0], m[1], match, Inference{Text: match, Confidence: LowConfidence, Reason: "a save DC isn't rolled"}, false})
+	}
+
+	for _, m := range inferBareDiceRegex.FindAllStringIndex(text, -1) {
+		match := text[m[0]:m[1]]
+		reason := "no roll type in the words around it"
+		if _, err := ParseDiceNotation(normalizeDice(match)); err != nil {
+			reason = "not valid dice notation"
+		}
+		add(inferredRoll{m[0], m[1], match, Inference{Text: match, Confidence: LowConfidence, Reason: reason}, true})
+	}
+
+	slices.SortFunc(rolls, func(a, b inferredRoll) int { return a.start - b.start })
+
+	var b strings.Builder
+	var inferences []Inference
+	bare := map[string]int{}
+	last := 0
+	for _, roll := range rolls {
+		b.WriteString(text[last:roll.start])
+		b.WriteString(roll.replacement)
+		last = roll.end
+
+		// Bare dice join the first note with the same reason
+		if roll.bare {
+			if i, ok := bare[roll.inference.Reason]; ok {
+				inferences[i].Text += ", " + roll.inference.Text
+				continue
+			}
+			bare[roll.inference.Reason] = len(inferences)
+		}
+		inferences = append(inferences, roll.inference)
+	}
+	b.WriteString(text[last:])
+
+	return b.String(), inferences
+}
+
+// rollTypeFor returns the roll type for the words after a roll: healing for
+// "hit points", damage for "damage", and "" for "temporary hit points", which
+// aren't healing
+func rollTypeFor(words string) string {
+	words = strings.ToLower(strings.TrimSpace(words))
+	switch {
+	case words == "temporary hit points":
+		return ""
+	case strings.HasSuffix(words, "hit points"):
+		return "healing"
+	default:
+		return "damage"
+	}
+}
+
+// temporaryHitPoints notes a roll of temporary hit points, which is left as
+// text
+func temporaryHitPoints(start, end int, text string) inferredRoll {
+	match := text[start:end]
+	return inferredRoll{start, end, match, Inference{Text: match, Confidence: LowConfidence, Reason: "temporary hit points aren't healing"}, false}
+}
+
+// normalizeDice removes the spaces and minus signs stat blocks typeset in
+// dice notation: "1d4 − 1" becomes "1d4-1"
+func normalizeDice(notation string) string {
+	return strings.NewReplacer(" ", "", "−", "-").Replace(strings.ToLower(notation))
+}
+
+// averageMatches reports whether a written average is the average of the
+// notation, rounded down as the books do or to the nearest as Average does
+func averageMatches(average, notation string) bool {
+	written, err := strconv.Atoi(average)
+	if err != nil {
+		return false
+	}
+	match := averageRegex.FindStringSubmatch(notation)
+	count, _ := strconv.Atoi(match[1])
+	sides, _ := strconv.Atoi(match[2])
+	modifier, _ := strconv.Atoi(match[3])
+	return written == Average(notation) || written == count*(sides+1)/2+modifier
+}
@@ -0,0 +1,90 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInferRolls(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		expected   string
+		inferences []string
+	}{
+		{
+			"stat block attack",
+			"Melee Weapon Attack: +5 to hit, reach 5 ft., one target. Hit: 10 (2d6 + 3) slashing damage.",
+			"Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 2d6+3 slashing damage.",
+			[]string{
+				`read "+5 to hit" as to hit: 1d20+5 (high confidence)`,
+				`read "10 (2d6 + 3) slashing damage" as damage: 2d6+3 (high confidence)`,
+			},
+		},
+		{
+			"damage without average",
+			"The target takes 3d6 fire damage.",
+			"The target takes damage: 3d6 fire damage.",
+			[]string{`read "3d6 fire damage" as damage: 3d6 (medium confidence: no average given)`},
+		},
+		{
+			"wrong average",
+			"The target takes 12 (3d6) fire damage.",
+			"The target takes damage: 3d6 fire damage.",
+			[]string{`read "12 (3d6) fire damage" as damage: 3d6 (medium confidence: average 12 doesn't match 3d6)`},
+		},
+		{
+			"average rounded down",
+			"Takes 3 (1d6) acid damage.",
+			"Takes damage: 1d6 acid damage.",
+			[]string{`read "3 (1d6) acid damage" as damage: 1d6 (high confidence)`},
+		},
+		{
+			"healing",
+			"You regain 1d8+4 hit points, or gain 1d8 temporary hit points.",
+			"You regain healing: 1d8+4 hit points, or gain 1d8 temporary hit points.",
+			[]string{
+				`read "1d8+4 hit points" as healing: 1d8+4 (high confidence)`,
+				`left "1d8 temporary hit points" as text (low confidence: temporary hit points aren't healing)`,
+			},
+		},
+		{
+			"temporary hit points with an average",
+			"It gains 4 (1d8) Temporary Hit Points.",
+			"It gains 4 (1d8) Temporary Hit Points.",
+			[]string{`left "4 (1d8) Temporary Hit Points" as text (low confidence: temporary hit points aren't healing)`},
+		},
+		{
+			"save DC and bare dice",
+			"Make a DC 13 Constitution saving throw, then roll 1d6, 2d4 or 9d7 damage and 1d4.",
+			"Make a DC 13 Constitution saving throw, then roll 1d6, 2d4 or 9d7 damage and 1d4.",
+			[]string{
+				`left "DC 13" as text (low confidence: a save DC isn't rolled)`,
+				`left "1d6, 2d4, 1d4" as text (low confidence: no roll type in the words around it)`,
+				`left "9d7" as text (low confidence: not valid dice notation)`,
+			},
+		},
+		{
+			"keywords untouched",
+			"to hit: 1d20+5 and damage: 1d8+3 slashing damage.",
+			"to hit: 1d20+5 and damage: 1d8+3 slashing damage.",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, inferences := InferRolls(tt.input)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+			var messages []string
+			for _, inference := range inferences {
+				messages = append(messages, inference.String())
+			}
+			if !reflect.DeepEqual(messages, tt.inferences) {
+				t.Errorf("Expected inferences %q, got %q", tt.inferences, messages)
+			}
+		})
+	}
+}
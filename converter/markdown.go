@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Pre-compiled patterns for inline markdown. Emphasis must start and end next
+// to a non-space character, so "2 * 3 * 4" is left alone; underscores only
+// count outside words, so snake_case_names are too.
+var (
+	markdownLinkRegex       = regexp.MustCompile(`!?\[([^\[\]]*)\]\([^()]*\)`)
+	markdownStrongRegex     = regexp.MustCompile(`\*\*(\S|\S.*?\S)\*\*`)
+	markdownEmphasisRegex   = regexp.MustCompile(`\*(\S|\S.*?\S)\*`)
+	markdownUnderscoreRegex = regexp.MustCompile(`(^|[^\p{L}\p{N}_])(__|_)(\S|\S.*?\S)(__|_)($|[^\p{L}\p{N}_])`)
+	markdownEscapeRegex     = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+)
+
+// escapeBase is the start of the private use area, where escaped punctuation
+// is parked while emphasis is stripped
+const escapeBase = 0xE000
+
+// StripMarkdown removes inline markdown that D&D Beyond would show literally:
+// *italic*, **bold**, _underline_ and their nested forms keep only their text,
+// [links](url) keep only the link text, and backslash escapes such as \*
+// become the plain character
+func StripMarkdown(text string) string {
+	// Park escaped characters so they can't act as delimiters
+	text = markdownEscapeRegex.ReplaceAllStringFunc(text, func(match string) string {
+		return string(rune(escapeBase + int(match[1])))
+	})
+
+	// Strip repeatedly so nested emphasis such as **bold *italic*** and
+	// adjacent _one_ _two_ are all removed
+	for {
+		stripped := markdownLinkRegex.ReplaceAllString(text, "$1")
+		stripped = markdownStrongRegex.ReplaceAllString(stripped, "$1")
+		stripped = markdownEmphasisRegex.ReplaceAllString(stripped, "$1")
+		stripped = markdownUnderscoreRegex.ReplaceAllStringFunc(stripped, stripUnderscores)
+		if stripped == text {
+			break
+		}
+		text = stripped
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r >= escapeBase && r < escapeBase+0x80 {
+			return r - escapeBase
+		}
+		return r
+	}, text)
+}
+
+// stripUnderscores removes the underscores of one _emphasis_ or __strong__
+// match when they pair up, keeping the characters around them
+func stripUnderscores(match string) string {
+	groups := markdownUnderscoreRegex.FindStringSubmatch(match)
+	if groups[2] != groups[4] {
+		return match
+	}
+	return groups[1] + groups[3] + groups[5]
+}
@@ -0,0 +1,46 @@
+package converter
+
+import "testing"
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain text", "Deals extra damage.", "Deals extra damage."},
+		{"italic", "You are *frightened* until the end of your turn.", "You are frightened until the end of your turn."},
+		{"bold", "You have **advantage** on the roll.", "You have advantage on the roll."},
+		{"underscore italic", "The target is _stunned_.", "The target is stunned."},
+		{"underscore bold", "The target is __prone__.", "The target is prone."},
+		{"bold italic", "***Never*** again.", "Never again."},
+		{"underscore bold italic", "___Never___ again.", "Never again."},
+		{"italic inside bold", "**Once per *long* rest** you can", "Once per long rest you can"},
+		{"bold inside italic", "*You gain **temporary** hit points*", "You gain temporary hit points"},
+		{"mixed delimiters", "**bold _and italic_**", "bold and italic"},
+		{"adjacent emphasis", "_one_ _two_ *three* *four*", "one two three four"},
+		{"link", "See [Grappling](https://example.com/rules#grappling) for details.", "See Grappling for details."},
+		{"link with emphasis", "[**Grappling**](rules.md)", "Grappling"},
+		{"emphasis around link", "*see [the rules](rules.md)*", "see the rules"},
+		{"image", "![Sigil](sigil.png) glows.", "Sigil glows."},
+		{"escaped asterisks", `Costs 5 gp \*per day\*.`, "Costs 5 gp *per day*."},
+		{"escaped inside emphasis", `**Note \*:** see below`, "Note *: see below"},
+		{"escaped underscore", `file\_name`, "file_name"},
+		{"escaped backslash", `a \\ b`, `a \ b`},
+		{"backslash before letter", `C:\Games`, `C:\Games`},
+		{"spaced asterisks", "2 * 3 * 4", "2 * 3 * 4"},
+		{"unmatched asterisk", "A * marks optional rules.", "A * marks optional rules."},
+		{"intraword underscores", "Uses snake_case_names.", "Uses snake_case_names."},
+		{"brackets without link", "[rollable] text [spell]", "[rollable] text [spell]"},
+		{"keywords untouched", "**Hit:** to hit: 1d20+5, damage: 1d8+3 and {{spell:Fire Bolt}}", "Hit: to hit: 1d20+5, damage: 1d8+3 and {{spell:Fire Bolt}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := StripMarkdown(tt.input)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package converter
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+)
+
+// RollResult is the outcome of rolling dice notation
+type RollResult struct {
+	Notation string `json:"notation"`
+	Rolls    []int  `json:"rolls"`
+	Modifier int    `json:"modifier"`
+	Total    int    `json:"total"`
+}
+
+// MaxDice is the most dice Roll will roll at once. Rolling allocates a slot
+// per die, so larger counts are rejected rather than rolled.
+const MaxDice = 100
+
+// Roll validates and rolls dice notation (e.g. "2d6+3") using rng
+func Roll(notation string, rng *rand.Rand) (RollResult, error) {
+	normalized, err := ParseDiceNotation(notation)
+	if err != nil {
+		return RollResult{}, err
+	}
+
+	// ParseDiceNotation guarantees the format, so the match always succeeds
+	match := averageRegex.FindStringSubmatch(normalized)
+
+	count, err := strconv.Atoi(match[1])
+	if err != nil || count > MaxDice {
+		return RollResult{}, fmt.Errorf("too many dice: %s (at most %d)", match[1], MaxDice)
+	}
+
+	sides := 0
+	modifier := 0
+	fmt.Sscanf(match[2], "%d", &sides)
+	if match[3] != "" {
+		fmt.Sscanf(match[3], "%d", &modifier)
+	}
+
+	result := RollResult{
+		Notation: normalized,
+		Rolls:    make([]int, count),
+		Modifier: modifier,
+		Total:    modifier,
+	}
+	for i := range result.Rolls {
+		result.Rolls[i] = rng.IntN(sides) + 1
+		result.Total += result.Rolls[i]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRoll_WithinBounds(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	tests := []struct {
+		notation string
+		count    int
+		min      int
+		max      int
+	}{
+		{"1d20+5", 1, 6, 25},
+		{"2d6-1", 2, 1, 11},
+		{"d8", 1, 1, 8},
+		{"4d4", 4, 4, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			for range 100 {
+				result, err := Roll(tt.notation, rng)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(result.Rolls) != tt.count {
+					t.Fatalf("Expected %d rolls, got %d", tt.count, len(result.Rolls))
+				}
+				if result.Total < tt.min || result.Total > tt.max {
+					t.Fatalf("Total %d outside [%d, %d]", result.Total, tt.min, tt.max)
+				}
+
+				sum := result.Modifier
+				for _, roll := range result.Rolls {
+					sum += roll
+				}
+				if sum != result.Total {
+					t.Fatalf("Expected total %d to equal rolls plus modifier %d", result.Total, sum)
+				}
+			}
+		})
+	}
+}
+
+func TestRoll_Normalizes(t *testing.T) {
+	result, err := Roll("d20+3", rand.New(rand.NewPCG(1, 2)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Notation != "1d20+3" || result.Modifier != 3 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestRoll_Invalid(t *testing.T) {
+	for _, notation := range []string{"1d3", "101d6", "1000000000000000000000d6"} {
+		if _, err := Roll(notation, rand.New(rand.NewPCG(1, 2))); err == nil {
+			t.Errorf("Expected error for %s, got nil", notation)
+		}
+	}
+}
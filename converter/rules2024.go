@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Pre-compiled patterns for stat-block wording
+var (
+	wording2024Regex    = regexp.MustCompile(`(?i)\b(?:melee or ranged|melee|ranged) attack roll:|\bsaving throw:[*_]*\s*DC \d+`)
+	attackRoll2024Regex = regexp.MustCompile(`(?i)\b((?:melee or ranged|melee|ranged) attack roll:[*_]*)\s*([+\-−]\s?\d+)`)
+	averageDiceRegex    = regexp.MustCompile(`(?i)\b\d+ ?\((\d*d\d+)(?:\s*([+\-−])\s*(\d+))?\)( [a-z]+)? (damage|hit points)\b`)
+)
+
+// Uses2024Wording reports whether text is written in the 2024 rules'
+// stat-block wording, such as "Melee Attack Roll: +5" or "Dexterity Saving
+// Throw: DC 15"
+func Uses2024Wording(text string) bool {
+	return wording2024Regex.MatchString(text)
+}
+
+// Convert2024 rewrites the 2024 rules' stat-block wording as roll keywords:
+// "Melee Attack Roll: +5" becomes "Melee Attack Roll: to hit: 1d20+5", with
+// any emphasis around the label kept, and average rolls are rewritten by
+// ConvertAverageRolls
+func Convert2024(text string) string {
+	text = attackRoll2024Regex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := attackRoll2024Regex.FindStringSubmatch(match)
+		return parts[1] + " " + ToHitKeyword(parts[2])
+	})
+
+	return ConvertAverageRolls(text)
+}
+
+// ToHitKeyword returns the to hit: roll for an attack bonus such as "+5" or
+// "− 1", as typeset in stat blocks
+func ToHitKeyword(bonus string) string {
+	bonus = strings.NewReplacer(" ", "", "−", "-").Replace(bonus)
+	if bonus == "+0" || bonus == "-0" {
+		return "to hit: 1d20"
+	}
+	return "to hit: 1d20" + bonus
+}
+
+// ConvertAverageRolls rewrites a stat block's average rolls as roll keywords:
+// "8 (1d8 + 3) slashing damage" becomes "damage: 1d8+3 slashing damage" and
+// "7 (2d4 + 2) hit points" becomes "healing: 2d4+2 hit points". Temporary
+// hit points and dice that ParseDiceNotation wouldn't accept are left as
+// written.
+func ConvertAverageRolls(text string) string {
+	return averageDiceRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := averageDiceRegex.FindStringSubmatch(match)
+		notation, err := ParseDiceNotation(normalizeDice(parts[1] + parts[2] + parts[3]))
+		if err != nil {
+			return match
+		}
+
+		words := parts[4] + " " + parts[5]
+		rollType := rollTypeFor(words)
+		if rollType == "" {
+			return match
+		}
+		return rollType + ": " + notation + words
+	})
+}
@@ -0,0 +1,80 @@
+package converter
+
+import "testing"
+
+func TestUses2024Wording(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected bool
+	}{
+		{"Melee Attack Roll: +5, reach 5 ft.", true},
+		{"*Melee or Ranged Attack Roll:* +4, reach 5 ft. or range 20/60 ft.", true},
+		{"*Dexterity Saving Throw:* DC 15, each creature in a 15-foot Cone.", true},
+		{"Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target.", false},
+		{"The target takes 7 (2d6) fire damage.", false},
+	}
+
+	for _, tt := range tests {
+		if result := Uses2024Wording(tt.text); result != tt.expected {
+			t.Errorf("Expected %v for %q, got %v", tt.expected, tt.text, result)
+		}
+	}
+}
+
+func TestConvert2024(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"attack roll",
+			"Melee Attack Roll: +5, reach 5 ft. Hit: 8 (1d8 + 3) Slashing damage.",
+			"Melee Attack Roll: to hit: 1d20+5, reach 5 ft. Hit: damage: 1d8+3 Slashing damage.",
+		},
+		{
+			"emphasis and minus sign",
+			"*Ranged Attack Roll:* −1, range 30/60 ft. *Hit:* 1 (1d4 − 1) Piercing damage plus 3 (1d6) Poison damage.",
+			"*Ranged Attack Roll:* to hit: 1d20-1, range 30/60 ft. *Hit:* damage: 1d4-1 Piercing damage plus damage: 1d6 Poison damage.",
+		},
+		{
+			"zero bonus",
+			"Melee Attack Roll: +0, reach 5 ft.",
+			"Melee Attack Roll: to hit: 1d20, reach 5 ft.",
+		},
+		{
+			"saving throw",
+			"Dexterity Saving Throw: DC 15, each creature in a 15-foot Cone. Failure: 27 (6d8) Fire damage. Success: Half damage.",
+			"Dexterity Saving Throw: DC 15, each creature in a 15-foot Cone. Failure: damage: 6d8 Fire damage. Success: Half damage.",
+		},
+		{
+			"healing",
+			"The troll regains 7 (2d4 + 2) Hit Points.",
+			"The troll regains healing: 2d4+2 Hit Points.",
+		},
+		{
+			"uppercase dice",
+			"Hit: 8 (1D8 − 1) Piercing damage.",
+			"Hit: damage: 1d8-1 Piercing damage.",
+		},
+		{
+			"temporary hit points",
+			"The knight gains 5 (1d10) Temporary Hit Points.",
+			"The knight gains 5 (1d10) Temporary Hit Points.",
+		},
+		{
+			"keywords untouched",
+			"Melee Weapon Attack: to hit: 1d20+5, reach 5 ft. Hit: damage: 1d8+3 slashing damage.",
+			"Melee Weapon Attack: to hit: 1d20+5, reach 5 ft. Hit: damage: 1d8+3 slashing damage.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Convert2024(tt.input)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -10,6 +10,15 @@ import (
 	"strings"
 )
 
+// Pattern to match {{spell:SpellName}}
+var spellPatternRegex = regexp.MustCompile(`\{\{spell:([^}]*)\}\}`)
+
+// SpellReference is a {{spell:Name}} reference found in text
+type SpellReference struct {
+	SpellName string
+	IsValid   bool
+}
+
 // LoadSpells loads the D&D 5e spell list from JSON file
 func LoadSpells() (map[string]bool, error) {
 	// Get the directory of this source file
@@ -55,12 +64,9 @@ func IsValidSpell(spellName string, spells map[string]bool) bool {
 func ConvertSpellLinks(text string, spells map[string]bool) (string, []string) {
 	warnings := []string{}
 
-	// Pattern to match {{spell:SpellName}}
-	spellPattern := regexp.MustCompile(`\{\{spell:([^}]*)\}\}`)
-
-	result := spellPattern.ReplaceAllStringFunc(text, func(match string) string {
+	result := spellPatternRegex.ReplaceAllStringFunc(text, func(match string) string {
 		// Extract spell name
-		submatches := spellPattern.FindStringSubmatch(match)
+		submatches := spellPatternRegex.FindStringSubmatch(match)
 		if len(submatches) < 2 {
 			return match
 		}
@@ -82,3 +88,19 @@ func ConvertSpellLinks(text string, spells map[string]bool) (string, []string) {
 
 	return result, warnings
 }
+
+// ExtractSpellReferences returns every {{spell:Name}} reference in text, in
+// order, noting whether each is in the spell list
+func ExtractSpellReferences(text string, spells map[string]bool) []SpellReference {
+	references := []SpellReference{}
+
+	for _, match := range spellPatternRegex.FindAllStringSubmatch(text, -1) {
+		spellName := strings.TrimSpace(match[1])
+		references = append(references, SpellReference{
+			SpellName: spellName,
+			IsValid:   IsValidSpell(spellName, spells),
+		})
+	}
+
+	return references
+}
@@ -187,3 +187,25 @@ func TestConvertSpellLinks_EmptySpellName(t *testing.T) {
 		t.Fatalf("Expected 1 warning, got %d", len(warnings))
 	}
 }
+
+func TestExtractSpellReferences(t *testing.T) {
+	spells := map[string]bool{"fireball": true, "shield": true}
+	text := "Cast {{spell:Fireball}}, {{spell:Made Up}} or {{spell: shield }}."
+
+	references := ExtractSpellReferences(text, spells)
+
+	expected := []SpellReference{
+		{SpellName: "Fireball", IsValid: true},
+		{SpellName: "Made Up", IsValid: false},
+		{SpellName: "shield", IsValid: true},
+	}
+
+	if len(references) != len(expected) {
+		t.Fatalf("Expected %d references, got %d: %v", len(expected), len(references), references)
+	}
+	for i := range expected {
+		if references[i] != expected[i] {
+			t.Errorf("Reference %d: expected %+v, got %+v", i, expected[i], references[i])
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"character-tool/clipboard"
+	"character-tool/converter"
+	"character-tool/parser"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var backendName string
+
+var copyCmd = &cobra.Command{
+	Use:   "copy [file]",
+	Short: "Copy formatted sections to the clipboard one at a time",
+	Long: `Formats a character and copies each section to the clipboard in turn.
+After each section is copied, paste it into D&D Beyond and press Enter to copy
+the next one. No output files are written.
+
+The clipboard backend is detected automatically (pbcopy on macOS, wl-copy on
+Wayland, xclip or xsel on X11, clip.exe on Windows and WSL, and the OSC 52
+terminal escape over SSH). Use --backend to choose one explicitly.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputs, err := collectInputs(args)
+		if err != nil {
+			return err
+		}
+		if len(inputs) != 1 {
+			return fmt.Errorf("copy takes exactly one input file, got %d", len(inputs))
+		}
+		sectionType, err := parseSectionFlag(section)
+		if err != nil {
+			return err
+		}
+
+		env := clipboard.DefaultEnvironment()
+		var backend clipboard.Backend
+		if backendName != "" {
+			backend, err = clipboard.ByName(backendName, env)
+		} else {
+			backend, err = clipboard.Detect(env)
+		}
+		if err != nil {
+			return err
+		}
+
+		cmd.SilenceUsage = true
+		return runCopy(inputs[0], backend, sectionType)
+	},
+}
+
+func init() {
+	copyCmd.Flags().BoolVar(&keepMd, "keep-markdown", false, keepMarkdownUsage)
+	copyCmd.Flags().StringVar(&backendName, "backend", "", "clipboard backend (pbcopy, wl-copy, xclip, xsel, clip.exe, osc52)")
+	rootCmd.AddCommand(copyCmd)
+}
+
+func runCopy(inputFile string, backend clipboard.Backend, only *parser.AbilityType) error {
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		return fmt.Errorf("failed to load spell list: %w", err)
+	}
+
+	sections, warnings, err := formatInput(inputFile, spells, only, keepMd)
+	if err != nil {
+		return err
+	}
+	printWarnings(os.Stdout, append(warnings, sectionWarnings(sections)...), verbose)
+	if len(sections) == 0 {
+		fmt.Println("No sections to copy")
+		return nil
+	}
+
+	items := make([]clipboard.Item, 0, len(sections))
+	for _, section := range sections {
+		items = append(items, clipboard.Item{Label: section.Type.SectionName(), Text: section.Text})
+	}
+
+	fmt.Printf("Copying %d section(s) with %s\n", len(items), backend.Name())
+	return clipboard.CopySequence(backend, items, os.Stdin, os.Stdout)
+}
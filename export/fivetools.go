@@ -0,0 +1,259 @@
+package export
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// attackRollHitRegex matches the "to hit" written after a 2024 attack roll's
+// bonus, which the wording leaves out
+var attackRollHitRegex = regexp.MustCompile(`(\{@atkr [^}]*\} \{@hit -?\d+\}) to hit`)
+
+// FiveToolsSource is the source abbreviation used for exported homebrew
+const FiveToolsSource = "CharacterTool"
+
+// fiveToolsAttacks maps attack wording to 5etools {@atk} codes
+var fiveToolsAttacks = map[string]string{
+	"Melee Weapon":           "mw",
+	"Ranged Weapon":          "rw",
+	"Melee or Ranged Weapon": "mw,rw",
+	"Melee Spell":            "ms",
+	"Ranged Spell":           "rs",
+	"Melee or Ranged Spell":  "ms,rs",
+}
+
+// fiveToolsAttackRolls maps the 2024 "Melee Attack Roll:" wording to 5etools
+// {@atkr} codes
+var fiveToolsAttackRolls = map[string]string{
+	"Melee":           "m",
+	"Ranged":          "r",
+	"Melee or Ranged": "m,r",
+}
+
+// FiveToolsHomebrew is a 5etools homebrew file holding one monster
+type FiveToolsHomebrew struct {
+	Meta    FiveToolsMeta      `json:"_meta"`
+	Monster []FiveToolsMonster `json:"monster"`
+}
+
+// FiveToolsMeta describes the homebrew source
+type FiveToolsMeta struct {
+	Sources []FiveToolsSourceInfo `json:"sources"`
+}
+
+// FiveToolsSourceInfo is one entry of _meta.sources
+type FiveToolsSourceInfo struct {
+	JSON         string   `json:"json"`
+	Abbreviation string   `json:"abbreviation"`
+	Full         string   `json:"full"`
+	Authors      []string `json:"authors"`
+	ConvertedBy  []string `json:"convertedBy"`
+	Version      string   `json:"version"`
+}
+
+// FiveToolsMonster holds the abilities of a creature. Statistics such as AC
+// and hit points aren't part of the markdown and must be added by hand.
+type FiveToolsMonster struct {
+	Name           string           `json:"name"`
+	Source         string           `json:"source"`
+	Trait          []FiveToolsEntry `json:"trait,omitempty"`
+	ActionHeader   []string         `json:"actionHeader,omitempty"`
+	Action         []FiveToolsEntry `json:"action,omitempty"`
+	BonusHeader    []string         `json:"bonusHeader,omitempty"`
+	Bonus          []FiveToolsEntry `json:"bonus,omitempty"`
+	ReactionHeader []string         `json:"reactionHeader,omitempty"`
+	Reaction       []FiveToolsEntry `json:"reaction,omitempty"`
+}
+
+// FiveToolsEntry is a named block of entries such as a trait or action.
+// Entries are paragraph strings or FiveToolsList values.
+type FiveToolsEntry struct {
+	Name    string `json:"name,omitempty"`
+	Entries []any  `json:"entries"`
+}
+
+// FiveToolsList is a bulleted list entry
+type FiveToolsList struct {
+	Type  string   `json:"type"`
+	Items []string `json:"items"`
+}
+
+// fiveToolsName writes an ability's name with its usage, using the
+// {@recharge} tag 5etools renders as a recharge roll
+func fiveToolsName(ability parser.Ability) string {
+	name := ability.Name
+	if recharge := ability.Usage.Recharge; recharge == 6 {
+		name += " {@recharge}"
+	} else if recharge > 0 {
+		name += fmt.Sprintf(" {@recharge %d}", recharge)
+	}
+
+	usage := ability.Usage
+	usage.Recharge = 0
+	if !usage.IsZero() {
+		name += " (" + usage.String() + ")"
+	}
+	return name
+}
+
+// BuildFiveToolsHomebrew converts a parse result into a 5etools homebrew file
+// with a single monster named name. Plain text paragraphs become section
+// headers, or unnamed traits in the Traits section.
+func BuildFiveToolsHomebrew(result *parser.ParseResult, spells map[string]bool, name string) (*FiveToolsHomebrew, []string, error) {
+	monster := FiveToolsMonster{Name: name, Source: FiveToolsSource}
+	var allWarnings []string
+
+	for _, section := range result.Sections() {
+		for _, ability := range section.Abilities {
+			entries, warnings, err := fiveToolsEntries(ability.RollText, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return nil, allWarnings, fmt.Errorf("failed to convert %s: %w", section.Type.SectionName(), err)
+			}
+
+			entry := FiveToolsEntry{Name: fiveToolsName(ability), Entries: entries}
+			// Plain text before the first named ability is the section's
+			// header; later plain text stays in order as an unnamed entry
+			switch section.Type {
+			case parser.Trait:
+				monster.Trait = append(monster.Trait, entry)
+			case parser.Action:
+				if ability.Name == "" && len(monster.Action) == 0 {
+					monster.ActionHeader = append(monster.ActionHeader, fiveToolsHeader(entries)...)
+					continue
+				}
+				monster.Action = append(monster.Action, entry)
+			case parser.BonusAction:
+				if ability.Name == "" && len(monster.Bonus) == 0 {
+					monster.BonusHeader = append(monster.BonusHeader, fiveToolsHeader(entries)...)
+					continue
+				}
+				monster.Bonus = append(monster.Bonus, entry)
+			case parser.Reaction:
+				if ability.Name == "" && len(monster.Reaction) == 0 {
+					monster.ReactionHeader = append(monster.ReactionHeader, fiveToolsHeader(entries)...)
+					continue
+				}
+				monster.Reaction = append(monster.Reaction, entry)
+			}
+		}
+	}
+
+	return &FiveToolsHomebrew{
+		Meta: FiveToolsMeta{Sources: []FiveToolsSourceInfo{{
+			JSON:         FiveToolsSource,
+			Abbreviation: "CT",
+			Full:         "Character Tool Homebrew",
+			Authors:      []string{},
+			ConvertedBy:  []string{"character-tool"},
+			Version:      "1.0.0",
+		}}},
+		Monster: []FiveToolsMonster{monster},
+	}, allWarnings, nil
+}
+
+// fiveToolsEntries converts a description into one entry per paragraph and
+// a list entry per list
+func fiveToolsEntries(description string, spells map[string]bool) ([]any, []string, error) {
+	var entries []any
+	var allWarnings []string
+
+	for _, block := range parser.Blocks(description) {
+		if len(block.Items) == 0 {
+			text, warnings, err := FiveToolsText(block.Text, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return nil, allWarnings, err
+			}
+			entries = append(entries, text)
+			continue
+		}
+
+		list := FiveToolsList{Type: "list"}
+		for _, item := range block.Items {
+			text, warnings, err := FiveToolsText(item, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return nil, allWarnings, err
+			}
+			list.Items = append(list.Items, text)
+		}
+		entries = append(entries, list)
+	}
+
+	return entries, allWarnings, nil
+}
+
+// fiveToolsHeader flattens entries into section header paragraphs, which
+// can only be strings; list items become paragraphs of their own
+func fiveToolsHeader(entries []any) []string {
+	var header []string
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case string:
+			header = append(header, entry)
+		case FiveToolsList:
+			header = append(header, entry.Items...)
+		}
+	}
+	return header
+}
+
+// FormatFiveTools encodes a homebrew file as indented JSON
+func FormatFiveTools(homebrew *FiveToolsHomebrew) (string, error) {
+	return encodeJSON(homebrew)
+}
+
+// FiveToolsText converts a markdown description to 5etools entry text with
+// {@hit}, {@damage}, {@dice}, {@d20}, {@spell}, {@atk}, {@atkr} and {@h} tags
+func FiveToolsText(description string, spells map[string]bool) (string, []string, error) {
+	text, warnings, err := convertDescription(description, spells, tagRewriter{
+		text: func(text string) string {
+			text = attackLabelRegex.ReplaceAllStringFunc(text, func(label string) string {
+				if label == "Hit:" {
+					return "{@h}"
+				}
+				if kind, ok := strings.CutSuffix(label, " Attack Roll:"); ok {
+					return "{@atkr " + fiveToolsAttackRolls[kind] + "}"
+				}
+				return "{@atk " + fiveToolsAttacks[strings.TrimSuffix(label, " Attack:")] + "}"
+			})
+			// {@h} is written directly before the damage
+			return strings.ReplaceAll(text, "{@h} ", "{@h}")
+		},
+		spell: func(spellName string, known bool) string {
+			// Unknown spells can't be looked up, so they stay plain names
+			if !known {
+				return spellName
+			}
+			return "{@spell " + strings.ToLower(spellName) + "}"
+		},
+		roll: func(display string, data converter.RollableData) string {
+			switch data.RollType {
+			case "to hit":
+				return "{@hit " + modifier(data.DiceNotation) + "} to hit"
+			case "damage":
+				return fmt.Sprintf("%d ({@damage %s})", converter.Average(data.DiceNotation), data.DiceNotation)
+			case "save":
+				if strings.HasPrefix(data.DiceNotation, "1d20") {
+					return "{@d20 " + modifier(data.DiceNotation) + "}"
+				}
+			}
+			return fmt.Sprintf("%d ({@dice %s})", converter.Average(data.DiceNotation), data.DiceNotation)
+		},
+	})
+	return attackRollHitRegex.ReplaceAllString(text, "$1"), warnings, err
+}
+
+// modifier returns the signed modifier of d20 notation without a leading
+// plus, as 5etools expects ("1d20+5" → "5", "1d20" → "0")
+func modifier(notation string) string {
+	value := strings.TrimPrefix(converter.DisplayValue(notation), "+")
+	if value == "" {
+		return "0"
+	}
+	return value
+}
@@ -0,0 +1,180 @@
+package export
+
+import (
+	"character-tool/converter"
+	"slices"
+	"testing"
+)
+
+func TestFormatFiveTools_MatchesSchema(t *testing.T) {
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	homebrew, warnings, err := BuildFiveToolsHomebrew(parseExample(t), spells, "Example Character")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	output, err := FormatFiveTools(homebrew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateJSON(t, "5etools-homebrew.schema.json", output)
+
+	monster := homebrew.Monster[0]
+	if len(monster.Trait) != 2 || len(monster.Action) != 2 || len(monster.Bonus) != 1 || len(monster.Reaction) != 1 {
+		t.Errorf("Expected 2/2/1/1 abilities, got %d/%d/%d/%d", len(monster.Trait), len(monster.Action), len(monster.Bonus), len(monster.Reaction))
+	}
+	if monster.Source != homebrew.Meta.Sources[0].JSON {
+		t.Errorf("Expected monster source %q to match _meta, got %q", homebrew.Meta.Sources[0].JSON, monster.Source)
+	}
+}
+
+func TestFiveToolsText(t *testing.T) {
+	spells := map[string]bool{"fireball": true}
+
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{
+			"weapon attack",
+			"Melee Weapon Attack: to hit: 1d20+5, reach 5 ft. Hit: damage: 1d8+3 slashing damage.",
+			"{@atk mw} {@hit 5} to hit, reach 5 ft. {@h}8 ({@damage 1d8+3}) slashing damage.",
+		},
+		{
+			"spell attack with zero and negative bonus",
+			"Melee or Ranged Spell Attack: to hit: d20 or to hit: 1d20-1.",
+			"{@atk ms,rs} {@hit 0} to hit or {@hit -1} to hit.",
+		},
+		{
+			"2024 attack roll",
+			"Melee or Ranged Attack Roll: to hit: 1d20+4, reach 5 ft. or range 20/60 ft. Hit: damage: 1d6+2 Piercing damage.",
+			"{@atkr m,r} {@hit 4}, reach 5 ft. or range 20/60 ft. {@h}6 ({@damage 1d6+2}) Piercing damage.",
+		},
+		{
+			"healing and save",
+			"Regain healing: 2d4+2 and make a save: 1d20+3.",
+			"Regain 7 ({@dice 2d4+2}) and make a {@d20 3}.",
+		},
+		{
+			"spells",
+			"Casts {{spell:Fireball}} and {{spell:Homebrew Blast}}.",
+			"Casts {@spell fireball} and Homebrew Blast.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := FiveToolsText(tt.description, spells)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBuildFiveToolsHomebrew_PlainTextHeaders(t *testing.T) {
+	result := parseMarkdown(t, "## Traits\n\nA note.\n\n## Actions\n\nThe creature acts twice.\n\n**Bite.** Chomp.\n\n## Reactions\n\nOnly when provoked.")
+
+	homebrew, _, err := BuildFiveToolsHomebrew(result, map[string]bool{}, "Wolf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monster := homebrew.Monster[0]
+	if len(monster.Trait) != 1 || monster.Trait[0].Name != "" || monster.Trait[0].Entries[0] != "A note." {
+		t.Errorf("Expected an unnamed trait, got %+v", monster.Trait)
+	}
+	if len(monster.ActionHeader) != 1 || monster.ActionHeader[0] != "The creature acts twice." {
+		t.Errorf("Expected action header, got %v", monster.ActionHeader)
+	}
+	if len(monster.Action) != 1 || monster.Action[0].Name != "Bite" {
+		t.Errorf("Expected Bite action, got %+v", monster.Action)
+	}
+	if len(monster.ReactionHeader) != 1 || len(monster.Reaction) != 0 {
+		t.Errorf("Expected only a reaction header, got %v and %+v", monster.ReactionHeader, monster.Reaction)
+	}
+}
+
+func TestBuildFiveToolsHomebrew_Usage(t *testing.T) {
+	result := parseMarkdown(t, "## Actions\n\n**Fire Breath (Recharge 5–6).** Fire.\n\n**Lightning (Recharge 6).** Zap.\n\n**Teleport (Recharge 4–6; 1/Day).** Gone.\n\n**Misty Step (3/Day).** Poof.")
+
+	homebrew, _, err := BuildFiveToolsHomebrew(result, map[string]bool{}, "Dragon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"Fire Breath {@recharge 5}", "Lightning {@recharge}", "Teleport {@recharge 4} (1/Day)", "Misty Step (3/Day)"}
+	actions := homebrew.Monster[0].Action
+	if len(actions) != len(expected) {
+		t.Fatalf("Expected %d actions, got %+v", len(expected), actions)
+	}
+	for i, name := range expected {
+		if actions[i].Name != name {
+			t.Errorf("Expected %q, got %q", name, actions[i].Name)
+		}
+	}
+}
+
+func TestBuildFiveToolsHomebrew_ParagraphsAndLists(t *testing.T) {
+	result := parseMarkdown(t, "## Actions\n\n**Wild Shape.** You become a beast.\n\nIt lasts an hour.\n\n- Wolf\n- Bear: damage: 1d8+4\n\n---\n\nOptions:\n\n- Fly\n- Swim")
+
+	homebrew, _, err := BuildFiveToolsHomebrew(result, map[string]bool{}, "Druid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := FormatFiveTools(homebrew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateJSON(t, "5etools-homebrew.schema.json", output)
+
+	entries := homebrew.Monster[0].Action[0].Entries
+	if len(entries) != 3 || entries[0] != "You become a beast." || entries[1] != "It lasts an hour." {
+		t.Fatalf("Expected two paragraphs and a list, got %+v", entries)
+	}
+	list, ok := entries[2].(FiveToolsList)
+	if !ok || list.Type != "list" || len(list.Items) != 2 || list.Items[1] != "Bear: 9 ({@damage 1d8+4})" {
+		t.Errorf("Expected a list entry, got %+v", entries[2])
+	}
+
+	after := homebrew.Monster[0].Action
+	if len(after) != 3 || after[1].Name != "" || after[1].Entries[0] != "Options:" || after[2].Name != "" {
+		t.Errorf("Expected the text after the ability as unnamed entries, got %+v", after)
+	}
+}
+
+func TestBuildFiveToolsHomebrew_HeaderBeforeFirstAbility(t *testing.T) {
+	result := parseMarkdown(t, "## Actions\n\nOptions:\n\n- Fly\n- Swim\n\n**Bite.** Chomp.\n\n---\n\nIt never flees.\n\n**Claw.** Scratch.")
+
+	homebrew, _, err := BuildFiveToolsHomebrew(result, map[string]bool{}, "Wolf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monster := homebrew.Monster[0]
+	if len(monster.ActionHeader) != 3 || monster.ActionHeader[2] != "Swim" {
+		t.Errorf("Expected list items flattened into the header, got %v", monster.ActionHeader)
+	}
+
+	var names []string
+	for _, action := range monster.Action {
+		names = append(names, action.Name)
+	}
+	if !slices.Equal(names, []string{"Bite", "", "Claw"}) {
+		t.Errorf("Expected later plain text in order between the actions, got %q", names)
+	}
+	if monster.Action[1].Entries[0] != "It never flees." {
+		t.Errorf("Expected the plain paragraph as an entry, got %+v", monster.Action[1])
+	}
+}
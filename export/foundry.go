@@ -0,0 +1,315 @@
+package export
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+// FoundrySpellCompendium is the dnd5e system compendium spell links point to
+const FoundrySpellCompendium = "dnd5e.spells"
+
+// foundryUsesPer maps usage periods to dnd5e recovery periods. Turn and
+// round limits have no equivalent and stay in the name only.
+var foundryUsesPer = map[string]string{
+	"Day":                "day",
+	"Short Rest":         "sr",
+	"Long Rest":          "lr",
+	"Short or Long Rest": "sr",
+}
+
+// foundryActivation maps sections to dnd5e activation types. Traits are passive.
+var foundryActivation = map[parser.AbilityType]string{
+	parser.Trait:       "",
+	parser.Action:      "action",
+	parser.BonusAction: "bonus",
+	parser.Reaction:    "reaction",
+}
+
+// FoundryActor is a dnd5e actor in the format accepted by Foundry's
+// "Import Data" option
+type FoundryActor struct {
+	Name   string         `json:"name"`
+	Type   string         `json:"type"`
+	System map[string]any `json:"system"`
+	Items  []FoundryItem  `json:"items"`
+	Flags  map[string]any `json:"flags"`
+}
+
+// FoundryItem is one feature of the actor
+type FoundryItem struct {
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	System FoundryItemSystem `json:"system"`
+	Flags  map[string]any    `json:"flags"`
+}
+
+// FoundryItemSystem holds the dnd5e data of a feature item
+type FoundryItemSystem struct {
+	Description FoundryDescription `json:"description"`
+	Activation  FoundryActivation  `json:"activation"`
+	ActionType  string             `json:"actionType"`
+	Attack      FoundryAttack      `json:"attack"`
+	Damage      FoundryDamage      `json:"damage"`
+	Formula     string             `json:"formula"`
+	Range       *FoundryRange      `json:"range,omitempty"`
+	Uses        *FoundryUses       `json:"uses,omitempty"`
+	Recharge    *FoundryRecharge   `json:"recharge,omitempty"`
+}
+
+// FoundryRange is an attack's reach or range. Long is the long range of
+// ranged attacks, 0 if there is none.
+type FoundryRange struct {
+	Value int    `json:"value"`
+	Long  int    `json:"long"`
+	Units string `json:"units"`
+}
+
+// FoundryUses limits how often the feature can be used. Per is "day", "sr"
+// or "lr".
+type FoundryUses struct {
+	Value int    `json:"value"`
+	Max   string `json:"max"`
+	Per   string `json:"per"`
+}
+
+// FoundryRecharge is the lowest d6 roll that recharges the feature
+type FoundryRecharge struct {
+	Value   int  `json:"value"`
+	Charged bool `json:"charged"`
+}
+
+// FoundryDescription is the item's HTML description
+type FoundryDescription struct {
+	Value string `json:"value"`
+	Chat  string `json:"chat"`
+}
+
+// FoundryActivation describes what using the feature costs
+type FoundryActivation struct {
+	Type      string `json:"type"`
+	Cost      *int   `json:"cost"`
+	Condition string `json:"condition"`
+}
+
+// FoundryAttack holds the attack bonus. Flat means the bonus is the whole
+// to-hit modifier rather than an addition to ability and proficiency.
+type FoundryAttack struct {
+	Bonus string `json:"bonus"`
+	Flat  bool   `json:"flat"`
+}
+
+// FoundryDamage holds [formula, damage type] pairs
+type FoundryDamage struct {
+	Parts     [][2]string `json:"parts"`
+	Versatile string      `json:"versatile"`
+}
+
+// BuildFoundryActor converts a parse result into a Foundry dnd5e actor named
+// name. Named abilities become feature items; plain text paragraphs become
+// the actor's biography.
+func BuildFoundryActor(result *parser.ParseResult, spells map[string]bool, name string) (*FoundryActor, []string, error) {
+	actor := &FoundryActor{
+		Name:  name,
+		Type:  "character",
+		Items: []FoundryItem{},
+		Flags: map[string]any{},
+	}
+
+	var biography []string
+	var allWarnings []string
+
+	for _, section := range result.Sections() {
+		for _, ability := range section.Abilities {
+			description, warnings, err := foundryDescription(ability.RollText, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return nil, allWarnings, fmt.Errorf("failed to convert %s: %w", section.Type.SectionName(), err)
+			}
+
+			if ability.Name == "" {
+				biography = append(biography, description)
+				continue
+			}
+
+			item, warnings := foundryItem(ability, description)
+			allWarnings = append(allWarnings, warnings...)
+			actor.Items = append(actor.Items, item)
+		}
+	}
+
+	actor.System = map[string]any{
+		"details": map[string]any{
+			"biography": map[string]any{"value": strings.Join(biography, "")},
+		},
+	}
+
+	return actor, allWarnings, nil
+}
+
+// FormatFoundry encodes an actor as indented JSON
+func FormatFoundry(actor *FoundryActor) (string, error) {
+	return encodeJSON(actor)
+}
+
+// foundryItem builds the feature item for a named ability
+func foundryItem(ability parser.Ability, description string) (FoundryItem, []string) {
+	var warnings []string
+
+	system := FoundryItemSystem{
+		Description: FoundryDescription{Value: description},
+		Activation:  FoundryActivation{Type: foundryActivation[ability.Type]},
+		Damage:      FoundryDamage{Parts: [][2]string{}},
+	}
+	if system.Activation.Type != "" {
+		cost := 1
+		system.Activation.Cost = &cost
+	}
+
+	usage := ability.Usage
+	if usage.Recharge > 0 {
+		system.Recharge = &FoundryRecharge{Value: usage.Recharge, Charged: true}
+	}
+	if per, ok := foundryUsesPer[usage.Per]; ok {
+		system.Uses = &FoundryUses{Value: usage.Uses, Max: strconv.Itoa(usage.Uses), Per: per}
+	}
+	if usage.Cost > 0 && strings.HasPrefix(usage.Resource, "Action") && ability.Type == parser.Action {
+		// Costs 2 Actions is a legendary action
+		system.Activation.Type = "legendary"
+		system.Activation.Cost = &usage.Cost
+	}
+
+	text := ability.RollText
+	damageTypes := DamageTypes(text)
+	damageIndex := 0
+
+	for _, rollable := range converter.ExtractRollables(text, ability.Name) {
+		switch rollable.RollType {
+		case "to hit":
+			if system.Attack.Bonus != "" {
+				warnings = append(warnings, fmt.Sprintf("%s: Foundry items have one attack bonus; ignored to hit: %s", ability.Name, rollable.DiceNotation))
+				continue
+			}
+			system.Attack = FoundryAttack{Bonus: strings.TrimPrefix(converter.DisplayValue(rollable.DiceNotation), "+"), Flat: true}
+			if system.Attack.Bonus == "" {
+				system.Attack.Bonus = "0"
+			}
+			system.ActionType = attackActionType(ability.Attack)
+		case "damage":
+			system.Damage.Parts = append(system.Damage.Parts, [2]string{rollable.DiceNotation, damageTypes[damageIndex]})
+			damageIndex++
+		case "healing":
+			system.Damage.Parts = append(system.Damage.Parts, [2]string{rollable.DiceNotation, "healing"})
+			if system.ActionType == "" {
+				system.ActionType = "heal"
+			}
+		case "save":
+			system.Formula = rollable.DiceNotation
+		}
+	}
+
+	if attack := ability.Attack; attack != nil {
+		system.Damage.Versatile = attack.Versatile
+		if attack.Range > 0 {
+			system.Range = &FoundryRange{Value: attack.Range, Long: attack.LongRange, Units: "ft"}
+		} else if attack.Reach > 0 {
+			system.Range = &FoundryRange{Value: attack.Reach, Units: "ft"}
+		}
+	}
+
+	if system.ActionType == "" && (len(system.Damage.Parts) > 0 || system.Formula != "") {
+		system.ActionType = "other"
+	}
+
+	return FoundryItem{
+		Name:   ability.Title(),
+		Type:   "feat",
+		System: system,
+		Flags: map[string]any{
+			"character-tool": map[string]any{"section": ability.Type.Slug()},
+		},
+	}, warnings
+}
+
+// attackActionType picks the dnd5e action type from the attack's kind,
+// defaulting to a melee weapon attack when there is no attack stat line
+func attackActionType(attack *parser.Attack) string {
+	kind := "m"
+	if attack != nil && attack.Ranged && !attack.Melee {
+		kind = "r"
+	}
+	if attack != nil && attack.Spell {
+		return kind + "sak"
+	}
+	return kind + "wak"
+}
+
+// foundryDescription converts a markdown description to Foundry HTML: dice
+// rolls become inline rolls and known spells become compendium links. Each
+// paragraph becomes a <p> and each list a <ul> or <ol>.
+func foundryDescription(description string, spells map[string]bool) (string, []string, error) {
+	var b strings.Builder
+	var allWarnings []string
+
+	for _, block := range parser.Blocks(description) {
+		if len(block.Items) == 0 {
+			text, warnings, err := foundryText(block.Text, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return "", allWarnings, err
+			}
+			b.WriteString("<p>" + text + "</p>")
+			continue
+		}
+
+		tag := "ul"
+		if block.Ordered {
+			tag = "ol"
+		}
+		b.WriteString("<" + tag + ">")
+		for _, item := range block.Items {
+			text, warnings, err := foundryText(item, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return "", allWarnings, err
+			}
+			b.WriteString("<li>" + text + "</li>")
+		}
+		b.WriteString("</" + tag + ">")
+	}
+
+	return b.String(), allWarnings, nil
+}
+
+// foundryText converts the text of one paragraph or list item to Foundry HTML
+func foundryText(text string, spells map[string]bool) (string, []string, error) {
+	return convertDescription(text, spells, tagRewriter{
+		text: template.HTMLEscapeString,
+		spell: func(spellName string, known bool) string {
+			if !known {
+				return template.HTMLEscapeString(spellName)
+			}
+			return FoundrySpellLink(spellName)
+		},
+		roll: func(display string, data converter.RollableData) string {
+			return FoundryInlineRoll(data.DiceNotation, display)
+		},
+	})
+}
+
+// FoundrySpellLink returns a link to a spell in the dnd5e spell compendium,
+// resolved by name when the actor is imported
+func FoundrySpellLink(spellName string) string {
+	return "@Compendium[" + FoundrySpellCompendium + "." + spellName + "]{" + spellName + "}"
+}
+
+// FoundryInlineRoll returns an inline roll labelled with the D&D Beyond display value
+func FoundryInlineRoll(notation, label string) string {
+	if label == "" {
+		return "[[/r " + notation + "]]"
+	}
+	return "[[/r " + notation + "]]{" + label + "}"
+}
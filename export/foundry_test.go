@@ -0,0 +1,249 @@
+package export
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"os"
+	"strings"
+	"testing"
+)
+
+func parseExample(t *testing.T) *parser.ParseResult {
+	t.Helper()
+	content, err := os.ReadFile("../testdata/example-character.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parseMarkdown(t, string(content))
+}
+
+func parseMarkdown(t *testing.T, markdown string) *parser.ParseResult {
+	t.Helper()
+	result, err := parser.ParseMarkdown(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestFormatFoundry_MatchesSchema(t *testing.T) {
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actor, warnings, err := BuildFoundryActor(parseExample(t), spells, "example character")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	output, err := FormatFoundry(actor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateJSON(t, "foundry-actor.schema.json", output)
+
+	if len(actor.Items) != 6 {
+		t.Errorf("Expected 6 items, got %d", len(actor.Items))
+	}
+}
+
+func TestBuildFoundryActor_Items(t *testing.T) {
+	spells := map[string]bool{"fireball": true}
+	result := &parser.ParseResult{
+		Traits: []parser.Ability{
+			{Name: "", Description: "A wandering scholar.", RollText: "A wandering scholar.", Type: parser.Trait},
+			{Name: "Spellcasting", Description: "Casts {{spell:Fireball}}.", RollText: "Casts {{spell:Fireball}}.", Type: parser.Trait},
+		},
+		Actions: []parser.Ability{
+			{Name: "Longsword", Description: "Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing plus damage: 2d6 fire damage.", RollText: "Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing plus damage: 2d6 fire damage.", Type: parser.Action},
+			{Name: "Fire Bolt", Description: "Ranged Spell Attack: to hit: d20+4. Hit: damage: 1d10 fire.", RollText: "Ranged Spell Attack: to hit: d20+4. Hit: damage: 1d10 fire.", Type: parser.Action},
+		},
+		BonusActions: []parser.Ability{
+			{Name: "Second Wind", Description: "Regain healing: 1d10+5 hit points.", RollText: "Regain healing: 1d10+5 hit points.", Type: parser.BonusAction},
+		},
+		Reactions: []parser.Ability{
+			{Name: "Resist", Description: "Make a save: 1d20+3.", RollText: "Make a save: 1d20+3.", Type: parser.Reaction},
+		},
+	}
+	// ParseMarkdown reads the attack stat lines of parsed abilities
+	for i := range result.Actions {
+		result.Actions[i].Attack, _ = parser.ParseAttack(result.Actions[i].Description)
+	}
+
+	actor, _, err := BuildFoundryActor(result, spells, "Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if biography := actor.System["details"].(map[string]any)["biography"].(map[string]any)["value"]; biography != "<p>A wandering scholar.</p>" {
+		t.Errorf("Expected plain text in biography, got %v", biography)
+	}
+
+	items := map[string]FoundryItem{}
+	for _, item := range actor.Items {
+		items[item.Name] = item
+	}
+	if len(items) != 5 {
+		t.Fatalf("Expected 5 items, got %d", len(items))
+	}
+
+	tests := []struct {
+		name       string
+		activation string
+		actionType string
+		bonus      string
+		parts      [][2]string
+		formula    string
+	}{
+		{"Spellcasting", "", "", "", [][2]string{}, ""},
+		{"Longsword", "action", "mwak", "5", [][2]string{{"1d8+3", "slashing"}, {"2d6", "fire"}}, ""},
+		{"Fire Bolt", "action", "rsak", "4", [][2]string{{"1d10", "fire"}}, ""},
+		{"Second Wind", "bonus", "heal", "", [][2]string{{"1d10+5", "healing"}}, ""},
+		{"Resist", "reaction", "other", "", [][2]string{}, "1d20+3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system := items[tt.name].System
+			if system.Activation.Type != tt.activation {
+				t.Errorf("Expected activation %q, got %q", tt.activation, system.Activation.Type)
+			}
+			if (system.Activation.Cost == nil) != (tt.activation == "") {
+				t.Errorf("Expected a cost only for active abilities, got %v", system.Activation.Cost)
+			}
+			if system.ActionType != tt.actionType {
+				t.Errorf("Expected action type %q, got %q", tt.actionType, system.ActionType)
+			}
+			if system.Attack.Bonus != tt.bonus {
+				t.Errorf("Expected attack bonus %q, got %q", tt.bonus, system.Attack.Bonus)
+			}
+			if len(system.Damage.Parts) != len(tt.parts) {
+				t.Fatalf("Expected damage parts %v, got %v", tt.parts, system.Damage.Parts)
+			}
+			for i := range tt.parts {
+				if system.Damage.Parts[i] != tt.parts[i] {
+					t.Errorf("Expected damage part %v, got %v", tt.parts[i], system.Damage.Parts[i])
+				}
+			}
+			if system.Formula != tt.formula {
+				t.Errorf("Expected formula %q, got %q", tt.formula, system.Formula)
+			}
+		})
+	}
+
+	description := items["Longsword"].System.Description.Value
+	if !strings.Contains(description, "[[/r 1d20+5]]{+5}") || !strings.Contains(description, "[[/r 1d8+3]]{8(1d8+3)}") {
+		t.Errorf("Expected inline rolls in description, got %s", description)
+	}
+	if spellcasting := items["Spellcasting"].System.Description.Value; spellcasting != "<p>Casts @Compendium[dnd5e.spells.Fireball]{Fireball}.</p>" {
+		t.Errorf("Expected compendium link, got %s", spellcasting)
+	}
+}
+
+func TestBuildFoundryActor_Usage(t *testing.T) {
+	result := parseMarkdown(t, "## Actions\n\n**Fire Breath (Recharge 5–6).** damage: 6d6 fire damage.\n\n**Misty Step (1/Short Rest).** You teleport.\n\n**Wing Attack (Costs 2 Actions).** The dragon beats its wings.")
+
+	actor, _, err := BuildFoundryActor(result, map[string]bool{}, "Dragon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actor.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(actor.Items))
+	}
+
+	breath, step, wing := actor.Items[0].System, actor.Items[1].System, actor.Items[2].System
+	if actor.Items[0].Name != "Fire Breath (Recharge 5–6)" {
+		t.Errorf("Expected the usage in the item name, got %q", actor.Items[0].Name)
+	}
+	if breath.Recharge == nil || *breath.Recharge != (FoundryRecharge{Value: 5, Charged: true}) || breath.Uses != nil {
+		t.Errorf("Expected recharge 5, got %+v and uses %+v", breath.Recharge, breath.Uses)
+	}
+	if step.Uses == nil || *step.Uses != (FoundryUses{Value: 1, Max: "1", Per: "sr"}) || step.Recharge != nil {
+		t.Errorf("Expected 1 use per short rest, got %+v and recharge %+v", step.Uses, step.Recharge)
+	}
+	if wing.Activation.Type != "legendary" || wing.Activation.Cost == nil || *wing.Activation.Cost != 2 {
+		t.Errorf("Expected a legendary action costing 2, got %+v", wing.Activation)
+	}
+}
+
+func TestBuildFoundryActor_AttackRange(t *testing.T) {
+	result := parseMarkdown(t, "## Actions\n\n**Longbow.** Ranged Weapon Attack: to hit: 1d20+5, range 150/600 ft., one target. Hit: damage: 1d8+3 piercing damage.\n\n"+
+		"**Longsword.** Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 1d8+3 slashing damage, or damage: 1d10+3 slashing damage if used with two hands.")
+
+	actor, _, err := BuildFoundryActor(result, map[string]bool{}, "Archer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actor.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(actor.Items))
+	}
+
+	bow, sword := actor.Items[0].System, actor.Items[1].System
+	if bow.ActionType != "rwak" || bow.Range == nil || *bow.Range != (FoundryRange{Value: 150, Long: 600, Units: "ft"}) {
+		t.Errorf("Expected a ranged weapon attack with range 150/600, got %q and %+v", bow.ActionType, bow.Range)
+	}
+	if sword.ActionType != "mwak" || sword.Range == nil || *sword.Range != (FoundryRange{Value: 5, Units: "ft"}) {
+		t.Errorf("Expected a melee weapon attack with reach 5, got %q and %+v", sword.ActionType, sword.Range)
+	}
+	if sword.Damage.Versatile != "1d10+3" {
+		t.Errorf("Expected versatile damage 1d10+3, got %q", sword.Damage.Versatile)
+	}
+}
+
+func TestBuildFoundryActor_ExtraAttackBonusWarns(t *testing.T) {
+	result := &parser.ParseResult{
+		Actions: []parser.Ability{
+			{Name: "Flurry", Description: "Attack: to hit: 1d20+5, then to hit: 1d20+3.", RollText: "Attack: to hit: 1d20+5, then to hit: 1d20+3.", Type: parser.Action},
+		},
+	}
+
+	actor, warnings, err := BuildFoundryActor(result, map[string]bool{}, "Test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %v", warnings)
+	}
+	if actor.Items[0].System.Attack.Bonus != "5" {
+		t.Errorf("Expected the first attack bonus, got %q", actor.Items[0].System.Attack.Bonus)
+	}
+}
+
+func TestFoundryDescription_EscapesHTML(t *testing.T) {
+	description, _, err := foundryDescription("Deals <b>damage: 1d6</b> & more.", map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "<p>Deals &lt;b&gt;[[/r 1d6]]{4(1d6)}&lt;/b&gt; &amp; more.</p>"
+	if description != expected {
+		t.Errorf("Expected %q, got %q", expected, description)
+	}
+}
+
+func TestFoundryDescription_UnknownSpellNotLinked(t *testing.T) {
+	description, warnings, err := foundryDescription("Casts {{spell:Homebrew Blast}}.", map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if description != "<p>Casts Homebrew Blast.</p>" {
+		t.Errorf("Expected plain spell name, got %q", description)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %v", warnings)
+	}
+}
+
+func TestFoundryDescription_ParagraphsAndLists(t *testing.T) {
+	description, _, err := foundryDescription("You become a beast.\n\n- Wolf\n- Bear: damage: 1d8+4\n\n1. First", map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "<p>You become a beast.</p><ul><li>Wolf</li><li>Bear: [[/r 1d8+4]]{9(1d8+4)}</li></ul><ol><li>First</li></ol>"
+	if description != expected {
+		t.Errorf("Expected %q, got %q", expected, description)
+	}
+}
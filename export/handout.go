@@ -0,0 +1,26 @@
+package export
+
+import (
+	"character-tool/converter"
+	"strings"
+)
+
+// HandoutText converts a markdown description for printing: rolls show their
+// D&D Beyond display value with a space before the dice ("+5", "8 (1d8+3)")
+// and spell names are wrapped in emphasis, such as "_" or "*"
+func HandoutText(description string, spells map[string]bool, emphasis string) (string, []string, error) {
+	return convertDescription(description, spells, tagRewriter{
+		text: func(text string) string {
+			return text
+		},
+		spell: func(spellName string, known bool) string {
+			return emphasis + spellName + emphasis
+		},
+		roll: func(display string, data converter.RollableData) string {
+			if display == "" {
+				return data.DiceNotation
+			}
+			return strings.Replace(display, "(", " (", 1)
+		},
+	})
+}
@@ -0,0 +1,43 @@
+package export
+
+import "testing"
+
+func TestHandoutText(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		emphasis    string
+		expected    string
+	}{
+		{
+			"attack",
+			"Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing damage.",
+			"*",
+			"Melee Weapon Attack: +5. Hit: 8 (1d8+3) slashing damage.",
+		},
+		{
+			"d20 without a modifier shows the dice",
+			"Roll to hit: 1d20 and heal healing: 2d4.",
+			"*",
+			"Roll 1d20 and heal 5 (2d4).",
+		},
+		{
+			"spells are emphasised",
+			"Casts {{spell:Fireball}} and {{spell:Made Up}}.",
+			"_",
+			"Casts _Fireball_ and _Made Up_.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := HandoutText(tt.description, map[string]bool{"fireball": true}, tt.emphasis)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
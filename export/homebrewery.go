@@ -0,0 +1,73 @@
+package export
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"fmt"
+	"strings"
+)
+
+// FormatHomebrewery renders a parse result as a Homebrewery V3 monster block
+// titled name. Only the ability sections are written; the statistics block
+// is left for the author to fill in.
+func FormatHomebrewery(result *parser.ParseResult, spells map[string]bool, name string) (string, []string, error) {
+	var b strings.Builder
+	var allWarnings []string
+
+	b.WriteString("{{monster,frame\n")
+	b.WriteString("## " + name + "\n")
+
+	for _, section := range result.Sections() {
+		if len(section.Abilities) == 0 {
+			continue
+		}
+
+		// Traits follow the statistics directly; other sections get a heading
+		if section.Type == parser.Trait {
+			b.WriteString("___\n")
+		} else {
+			b.WriteString("\n### " + section.Type.SectionName() + "\n")
+		}
+
+		paragraphs := make([]string, 0, len(section.Abilities))
+		for _, ability := range section.Abilities {
+			text, warnings, err := HomebreweryText(ability.RollText, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return "", allWarnings, fmt.Errorf("failed to convert %s: %w", section.Type.SectionName(), err)
+			}
+
+			if ability.Name != "" {
+				text = "***" + ability.Title() + ".*** " + text
+			}
+			paragraphs = append(paragraphs, text)
+		}
+
+		// A lone colon adds the vertical space between abilities
+		b.WriteString(strings.Join(paragraphs, "\n:\n") + "\n")
+	}
+
+	b.WriteString("}}\n")
+	return b.String(), allWarnings, nil
+}
+
+// HomebreweryText converts a markdown description to Homebrewery markdown in
+// stat-block style: italic spell names and attack labels, "+5 to hit" and
+// "8 (1d8 + 3)" damage
+func HomebreweryText(description string, spells map[string]bool) (string, []string, error) {
+	return convertDescription(description, spells, tagRewriter{
+		text: func(text string) string {
+			return attackLabelRegex.ReplaceAllString(text, "*$0*")
+		},
+		spell: func(spellName string, known bool) string {
+			// Homebrew spells keep the author's capitalization
+			if !known {
+				return "*" + spellName + "*"
+			}
+			return "*" + strings.ToLower(spellName) + "*"
+		},
+		roll: func(display string, data converter.RollableData) string {
+			return statBlockRoll(data, "−")
+		},
+	})
+}
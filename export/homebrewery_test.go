@@ -0,0 +1,77 @@
+package export
+
+import (
+	"testing"
+)
+
+func TestFormatHomebrewery(t *testing.T) {
+	spells := map[string]bool{"fireball": true, "shield": true}
+	result := parseMarkdown(t, `## Traits
+
+**Spellcasting.** Casts {{spell:Fireball}}.
+
+---
+
+A quiet scholar.
+
+## Actions
+
+**Longsword.** Melee Weapon Attack: to hit: 1d20+5, reach 5 ft. Hit: damage: 1d8+3 slashing damage.
+
+**Weak Jab.** Melee Weapon Attack: to hit: 1d20-1. Hit: damage: 1d4-1 piercing damage.
+
+## Reactions
+
+**Parry.** Cast {{spell:Shield}}.`)
+
+	output, warnings, err := FormatHomebrewery(result, spells, "Knight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	expected := `{{monster,frame
+## Knight
+___
+***Spellcasting.*** Casts *fireball*.
+:
+A quiet scholar.
+
+### Actions
+***Longsword.*** *Melee Weapon Attack:* +5 to hit, reach 5 ft. *Hit:* 8 (1d8 + 3) slashing damage.
+:
+***Weak Jab.*** *Melee Weapon Attack:* -1 to hit. *Hit:* 2 (1d4 − 1) piercing damage.
+
+### Reactions
+***Parry.*** Cast *shield*.
+}}
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+}
+
+func TestFormatHomebrewery_EmptyResult(t *testing.T) {
+	output, _, err := FormatHomebrewery(parseMarkdown(t, ""), map[string]bool{}, "Empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "{{monster,frame\n## Empty\n}}\n"; output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+func TestHomebreweryText_UnknownSpell(t *testing.T) {
+	result, warnings, err := HomebreweryText("Casts {{spell:Homebrew Blast}} for damage: 2d6 fire.", map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Casts *Homebrew Blast* for 7 (2d6) fire."; result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Expected 1 warning for the unknown spell, got %v", warnings)
+	}
+}
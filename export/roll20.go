@@ -0,0 +1,267 @@
+package export
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"fmt"
+	"strings"
+)
+
+// roll20MaxDamage is the number of damage rolls the 5e OGL sheet templates display
+const roll20MaxDamage = 2
+
+// roll20TextReplacer makes plain text safe inside a template field
+var roll20TextReplacer = strings.NewReplacer("{", "(", "}", ")")
+
+// Roll20Character is a character in the VTT Enhancement Suite JSON format,
+// the common way to import characters and their abilities into Roll20
+type Roll20Character struct {
+	SchemaVersion    int             `json:"schema_version"`
+	Name             string          `json:"name"`
+	Avatar           string          `json:"avatar"`
+	Bio              string          `json:"bio"`
+	GMNotes          string          `json:"gmnotes"`
+	DefaultToken     string          `json:"defaulttoken"`
+	Tags             string          `json:"tags"`
+	ControlledBy     string          `json:"controlledby"`
+	InPlayerJournals string          `json:"inplayerjournals"`
+	Attribs          []any           `json:"attribs"`
+	Abilities        []Roll20Ability `json:"abilities"`
+}
+
+// Roll20Ability is a macro stored on the character sheet
+type Roll20Ability struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	IsTokenAction bool   `json:"istokenaction"`
+	Action        string `json:"action"`
+	Order         int    `json:"order"`
+}
+
+// BuildRoll20Character converts the actions, bonus actions and reactions that
+// have rollables into Roll20 ability macros. Traits and abilities without
+// rolls are left out.
+func BuildRoll20Character(result *parser.ParseResult, spells map[string]bool, name string) (*Roll20Character, []string, error) {
+	character := &Roll20Character{
+		SchemaVersion: 2,
+		Name:          name,
+		Tags:          "[]",
+		Attribs:       []any{},
+		Abilities:     []Roll20Ability{},
+	}
+	var allWarnings []string
+
+	for _, section := range result.Sections() {
+		if section.Type == parser.Trait {
+			continue
+		}
+
+		for _, ability := range section.Abilities {
+			if ability.Name == "" {
+				continue
+			}
+
+			macro, warnings, err := Roll20Macro(ability, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return nil, allWarnings, fmt.Errorf("failed to convert %s: %w", section.Type.SectionName(), err)
+			}
+			if macro == "" {
+				continue
+			}
+
+			character.Abilities = append(character.Abilities, Roll20Ability{
+				Name:          ability.Title(),
+				Description:   ability.Type.String(),
+				IsTokenAction: true,
+				Action:        macro,
+				Order:         len(character.Abilities),
+			})
+		}
+	}
+
+	return character, allWarnings, nil
+}
+
+// Roll20Macro builds the roll template macro for an ability, or "" when it
+// has no rollables. Attacks use the 5e OGL sheet's atk template (atkdmg when
+// they also deal damage), damage and healing alone use dmg, and saves use
+// simple.
+func Roll20Macro(ability parser.Ability, spells map[string]bool) (string, []string, error) {
+	text := ability.RollText
+	rollables := converter.ExtractRollables(text, ability.Name)
+	if len(rollables) == 0 {
+		return "", nil, nil
+	}
+
+	description, warnings, err := roll20Description(text, spells)
+	if err != nil {
+		return "", warnings, err
+	}
+
+	var attack, save string
+	var damage [][2]string
+	damageTypes := DamageTypes(text)
+	damageIndex := 0
+
+	for _, rollable := range rollables {
+		switch rollable.RollType {
+		case "to hit":
+			if attack != "" {
+				warnings = append(warnings, fmt.Sprintf("%s: Roll20 macros have one attack roll; ignored to hit: %s", ability.Name, rollable.DiceNotation))
+				continue
+			}
+			attack = rollable.DiceNotation
+		case "damage":
+			damage = append(damage, [2]string{rollable.DiceNotation, damageTypes[damageIndex]})
+			damageIndex++
+		case "healing":
+			damage = append(damage, [2]string{rollable.DiceNotation, "Healing"})
+		case "save":
+			if save != "" {
+				warnings = append(warnings, fmt.Sprintf("%s: Roll20 macros have one save roll; ignored save: %s", ability.Name, rollable.DiceNotation))
+				continue
+			}
+			save = rollable.DiceNotation
+		}
+	}
+
+	if len(damage) > roll20MaxDamage {
+		for _, part := range damage[roll20MaxDamage:] {
+			warnings = append(warnings, fmt.Sprintf("%s: Roll20 templates show %d damage rolls; ignored %s", ability.Name, roll20MaxDamage, part[0]))
+		}
+		damage = damage[:roll20MaxDamage]
+	}
+
+	var fields []string
+	field := func(key, value string) {
+		fields = append(fields, "{{"+key+"="+value+"}}")
+	}
+
+	var templateName string
+	switch {
+	case attack != "":
+		templateName = "atk"
+		if len(damage) > 0 {
+			templateName = "atkdmg"
+		}
+		field("mod", converter.DisplayValue(attack))
+		field("rname", ability.Title())
+		if reach := roll20Range(ability.Attack); reach != "" {
+			field("range", reach)
+		}
+		field("r1", roll20InlineRoll(attack))
+		field("always", "1")
+		field("r2", roll20InlineRoll(attack))
+		field("attack", "1")
+	case len(damage) > 0:
+		templateName = "dmg"
+		field("rname", ability.Title())
+	default:
+		templateName = "simple"
+		field("rname", ability.Title())
+		field("mod", converter.DisplayValue(save))
+		field("r1", roll20InlineRoll(save))
+		field("always", "1")
+		field("r2", roll20InlineRoll(save))
+	}
+
+	if save != "" && templateName != "simple" {
+		warnings = append(warnings, fmt.Sprintf("%s: Roll20 macros can't combine a save with other rolls; ignored save: %s", ability.Name, save))
+	}
+
+	if len(damage) > 0 {
+		field("damage", "1")
+		for i, part := range damage {
+			n := fmt.Sprint(i + 1)
+			field("dmg"+n+"flag", "1")
+			field("dmg"+n, roll20InlineRoll(part[0]))
+			field("dmg"+n+"type", part[1])
+		}
+	}
+
+	field("desc", description)
+
+	return "&{template:" + templateName + "} " + strings.Join(fields, " "), warnings, nil
+}
+
+// roll20Range writes an attack's reach or range as the 5e sheet does, such
+// as "5 ft" or "80/320 ft", or "" if it has neither
+func roll20Range(attack *parser.Attack) string {
+	switch {
+	case attack == nil:
+		return ""
+	case attack.Range > 0 && attack.LongRange > 0:
+		return fmt.Sprintf("%d/%d ft", attack.Range, attack.LongRange)
+	case attack.Range > 0:
+		return fmt.Sprintf("%d ft", attack.Range)
+	case attack.Reach > 0:
+		return fmt.Sprintf("%d ft", attack.Reach)
+	}
+	return ""
+}
+
+// FormatRoll20Macros lists each ability's macro under its name, ready to
+// paste into Roll20's ability editor
+func FormatRoll20Macros(character *Roll20Character) string {
+	var blocks []string
+	for _, ability := range character.Abilities {
+		blocks = append(blocks, ability.Name+" ("+ability.Description+")\n"+ability.Action)
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// FormatRoll20JSON encodes a character as indented JSON
+func FormatRoll20JSON(character *Roll20Character) (string, error) {
+	return encodeJSON(character)
+}
+
+// roll20Description converts a description for a template's desc field: rolls
+// show their D&D Beyond display value and spells their name. Braces would end
+// the template field early, so they are replaced. Each line of a macro is
+// sent separately, so paragraphs and list items are joined on one line.
+func roll20Description(description string, spells map[string]bool) (string, []string, error) {
+	var parts []string
+	var allWarnings []string
+
+	for _, block := range parser.Blocks(description) {
+		texts := []string{block.Text}
+		if len(block.Items) > 0 {
+			texts = block.Items
+		}
+		for _, text := range texts {
+			converted, warnings, err := roll20Text(text, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return "", allWarnings, err
+			}
+			if len(block.Items) > 0 {
+				converted = "• " + converted
+			}
+			parts = append(parts, strings.Join(strings.Fields(converted), " "))
+		}
+	}
+
+	return strings.Join(parts, " "), allWarnings, nil
+}
+
+// roll20Text converts the text of one paragraph or list item for a desc field
+func roll20Text(text string, spells map[string]bool) (string, []string, error) {
+	return convertDescription(text, spells, tagRewriter{
+		text: roll20TextReplacer.Replace,
+		spell: func(spellName string, known bool) string {
+			return spellName
+		},
+		roll: func(display string, data converter.RollableData) string {
+			if display == "" {
+				return data.DiceNotation
+			}
+			return display
+		},
+	})
+}
+
+// roll20InlineRoll returns an inline roll for normalized dice notation
+func roll20InlineRoll(notation string) string {
+	return "[[" + notation + "]]"
+}
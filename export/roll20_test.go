@@ -0,0 +1,138 @@
+package export
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"strings"
+	"testing"
+)
+
+func TestFormatRoll20JSON_MatchesSchema(t *testing.T) {
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	character, warnings, err := BuildRoll20Character(parseExample(t), spells, "example character")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	output, err := FormatRoll20JSON(character)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateJSON(t, "roll20-character.schema.json", output)
+
+	// Traits and the roll-less Shield reaction are left out
+	names := []string{}
+	for _, ability := range character.Abilities {
+		names = append(names, ability.Name)
+	}
+	if strings.Join(names, ",") != "Quarterstaff,Fire Bolt,Second Wind" {
+		t.Errorf("Expected abilities with rolls only, got %v", names)
+	}
+}
+
+func TestRoll20Macro(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+		warnings    int
+	}{
+		{
+			"attack with damage",
+			"Melee Weapon Attack: to hit: d20+5. Hit: damage: 1d8+3 slashing.",
+			"&{template:atkdmg} {{mod=+5}} {{rname=Test}} {{r1=[[1d20+5]]}} {{always=1}} {{r2=[[1d20+5]]}} {{attack=1}} " +
+				"{{damage=1}} {{dmg1flag=1}} {{dmg1=[[1d8+3]]}} {{dmg1type=slashing}} " +
+				"{{desc=Melee Weapon Attack: +5. Hit: 8(1d8+3) slashing.}}",
+			0,
+		},
+		{
+			"attack only",
+			"Shove: to hit: 1d20+4.",
+			"&{template:atk} {{mod=+4}} {{rname=Test}} {{r1=[[1d20+4]]}} {{always=1}} {{r2=[[1d20+4]]}} {{attack=1}} {{desc=Shove: +4.}}",
+			0,
+		},
+		{
+			"healing",
+			"Regain healing: 2d4+2 hit points.",
+			"&{template:dmg} {{rname=Test}} {{damage=1}} {{dmg1flag=1}} {{dmg1=[[2d4+2]]}} {{dmg1type=Healing}} {{desc=Regain 7(2d4+2) hit points.}}",
+			0,
+		},
+		{
+			"save",
+			"Roll a save: 1d20.",
+			"&{template:simple} {{rname=Test}} {{mod=}} {{r1=[[1d20]]}} {{always=1}} {{r2=[[1d20]]}} {{desc=Roll a 1d20.}}",
+			0,
+		},
+		{
+			"third damage roll dropped",
+			"damage: 1d6 fire, damage: 1d6 cold and damage: 1d6 acid.",
+			"&{template:dmg} {{rname=Test}} {{damage=1}} {{dmg1flag=1}} {{dmg1=[[1d6]]}} {{dmg1type=fire}} {{dmg2flag=1}} {{dmg2=[[1d6]]}} {{dmg2type=cold}} " +
+				"{{desc=4(1d6) fire, 4(1d6) cold and 4(1d6) acid.}}",
+			1,
+		},
+		{
+			"save ignored beside attack",
+			"to hit: 1d20+2, or save: 1d20+1.",
+			"&{template:atk} {{mod=+2}} {{rname=Test}} {{r1=[[1d20+2]]}} {{always=1}} {{r2=[[1d20+2]]}} {{attack=1}} {{desc=+2, or +1.}}",
+			1,
+		},
+		{
+			"braces and unknown spells",
+			"Casts {{spell:Not A Spell}} {odd}: damage: 1d4.",
+			"&{template:dmg} {{rname=Test}} {{damage=1}} {{dmg1flag=1}} {{dmg1=[[1d4]]}} {{dmg1type=}} {{desc=Casts Not A Spell (odd): 3(1d4).}}",
+			1,
+		},
+		{
+			"no rollables",
+			"Nothing to roll.",
+			"",
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ability := parser.Ability{Name: "Test", Description: tt.description, RollText: tt.description, Type: parser.Action}
+			macro, warnings, err := Roll20Macro(ability, map[string]bool{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if macro != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, macro)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("Expected %d warnings, got %v", tt.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestFormatRoll20Macros(t *testing.T) {
+	character := &Roll20Character{Abilities: []Roll20Ability{
+		{Name: "Bite", Description: "Action", Action: "&{template:atk}"},
+		{Name: "Parry", Description: "Reaction", Action: "&{template:simple}"},
+	}}
+
+	expected := "Bite (Action)\n&{template:atk}\n\nParry (Reaction)\n&{template:simple}"
+	if result := FormatRoll20Macros(character); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRoll20Description_OneLine(t *testing.T) {
+	description, _, err := roll20Description("You become a beast\nyou have seen.\n\n- Wolf\n- Bear: damage: 1d8+4", map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "You become a beast you have seen. • Wolf • Bear: 9(1d8+4)"
+	if description != expected {
+		t.Errorf("Expected %q, got %q", expected, description)
+	}
+}
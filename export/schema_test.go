@@ -0,0 +1,145 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+)
+
+// schema is the subset of JSON Schema used by the fixtures in testdata
+type schema struct {
+	Type       any                `json:"type"`
+	Required   []string           `json:"required"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+	Enum       []any              `json:"enum"`
+	MinItems   *int               `json:"minItems"`
+	MaxItems   *int               `json:"maxItems"`
+}
+
+// loadSchema reads a schema fixture from testdata
+func loadSchema(t *testing.T, name string) *schema {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("invalid schema %s: %v", name, err)
+	}
+	return &s
+}
+
+// validateJSON checks an encoded document against a schema fixture and
+// reports every violation
+func validateJSON(t *testing.T, schemaName, document string) {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	for _, violation := range loadSchema(t, schemaName).validate(value, "$") {
+		t.Error(violation)
+	}
+}
+
+// validate returns a message for each place value doesn't match the schema
+func (s *schema) validate(value any, path string) []string {
+	if !s.typeMatches(value) {
+		return []string{fmt.Sprintf("%s: expected type %v, got %T", path, s.Type, value)}
+	}
+	var violations []string
+
+	if s.Enum != nil && !slices.Contains(s.Enum, value) {
+		violations = append(violations, fmt.Sprintf("%s: %v is not one of %v", path, value, s.Enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: missing required property %q", path, key))
+			}
+		}
+		for key, property := range s.Properties {
+			if child, ok := v[key]; ok {
+				violations = append(violations, property.validate(child, path+"."+key)...)
+			}
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			violations = append(violations, fmt.Sprintf("%s: expected at least %d items, got %d", path, *s.MinItems, len(v)))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			violations = append(violations, fmt.Sprintf("%s: expected at most %d items, got %d", path, *s.MaxItems, len(v)))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				violations = append(violations, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return violations
+}
+
+// typeMatches reports whether value has one of the schema's types
+func (s *schema) typeMatches(value any) bool {
+	var types []string
+	switch t := s.Type.(type) {
+	case nil:
+		return true
+	case string:
+		types = []string{t}
+	case []any:
+		for _, name := range t {
+			types = append(types, fmt.Sprint(name))
+		}
+	}
+
+	for _, name := range types {
+		switch v := value.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case float64:
+			if name == "number" || (name == "integer" && v == float64(int64(v))) {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case []any:
+			if name == "array" {
+				return true
+			}
+		case map[string]any:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestValidate_ReportsViolations(t *testing.T) {
+	s := loadSchema(t, "foundry-actor.schema.json")
+
+	var value any
+	json.Unmarshal([]byte(`{"name": 3, "type": "vehicle", "items": [{}]}`), &value)
+
+	violations := s.validate(value, "$")
+	// name type, type enum, missing system and flags, and the item's four required keys
+	if len(violations) != 8 {
+		t.Errorf("Expected 8 violations, got %d: %v", len(violations), violations)
+	}
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"character-tool/converter"
+	"fmt"
+	"strings"
+)
+
+// StatBlockText converts a markdown description to plain stat-block text:
+// "+5 to hit", "8 (1d8 + 3)" damage and spell names as written. Most VTTs
+// and encounter trackers detect dice in this wording and make it rollable.
+func StatBlockText(description string, spells map[string]bool) (string, []string, error) {
+	return convertDescription(description, spells, tagRewriter{
+		text: func(text string) string {
+			return text
+		},
+		spell: func(spellName string, known bool) string {
+			return spellName
+		},
+		roll: func(display string, data converter.RollableData) string {
+			return statBlockRoll(data, "-")
+		},
+	})
+}
+
+// statBlockRoll writes a rollable the way stat blocks do: d20 rolls as a
+// signed modifier ("+5 to hit" for attacks), others as "8 (1d8 + 3)". minus
+// is the sign used for negative modifiers in dice notation.
+func statBlockRoll(data converter.RollableData, minus string) string {
+	if strings.HasPrefix(data.DiceNotation, "1d20") && (data.RollType == "to hit" || data.RollType == "save") {
+		value := modifier(data.DiceNotation)
+		if !strings.HasPrefix(value, "-") {
+			value = "+" + value
+		}
+		if data.RollType == "to hit" {
+			value += " to hit"
+		}
+		return value
+	}
+	return fmt.Sprintf("%d (%s)", converter.Average(data.DiceNotation), spacedNotation(data.DiceNotation, minus))
+}
+
+// spacedNotation writes the modifier of dice notation with spaces around
+// the sign: "1d8+3" → "1d8 + 3"
+func spacedNotation(notation, minus string) string {
+	if i := strings.IndexAny(notation, "+-"); i > 0 {
+		sign := "+"
+		if notation[i] == '-' {
+			sign = minus
+		}
+		return notation[:i] + " " + sign + " " + notation[i+1:]
+	}
+	return notation
+}
@@ -0,0 +1,39 @@
+package export
+
+import "testing"
+
+func TestStatBlockText(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{
+			"attack",
+			"Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing damage.",
+			"Melee Weapon Attack: +5 to hit. Hit: 8 (1d8 + 3) slashing damage.",
+		},
+		{
+			"negative modifiers use ASCII minus",
+			"to hit: 1d20-1, damage: 1d4-1.",
+			"-1 to hit, 2 (1d4 - 1).",
+		},
+		{
+			"save and spells",
+			"Make a save: 1d20+2 against {{spell:Fireball}}.",
+			"Make a +2 against Fireball.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := StatBlockText(tt.description, map[string]bool{"fireball": true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"character-tool/converter"
+	"character-tool/formatter"
+	"character-tool/parser"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Pre-compiled patterns shared by the exporters
+var (
+	ddbTagRegex      = regexp.MustCompile(`\[rollable\]([^;\[]*);(\{.*?\})\[/rollable\]|\[spell\](.*?)\[/spell\]`)
+	attackLabelRegex = regexp.MustCompile(`\b(Melee or Ranged|Melee|Ranged) (?:(Weapon|Spell) Attack|Attack Roll):|\bHit:`)
+	damageTypeRegex  = regexp.MustCompile(`damage:\s*(\d*d\d+[+-]?\d*)(?:\s+((?i:acid|bludgeoning|cold|fire|force|lightning|necrotic|piercing|poison|psychic|radiant|slashing|thunder))\b)?`)
+)
+
+// tagRewriter says how each part of D&D Beyond text appears in another format
+type tagRewriter struct {
+	// text converts the plain text between tags
+	text func(text string) string
+	// spell replaces a [spell] tag; known reports whether it is in the spell list
+	spell func(spellName string, known bool) string
+	// roll replaces a [rollable] tag given its display value and data
+	roll func(display string, data converter.RollableData) string
+}
+
+// convertDescription converts a markdown description with the same converters
+// as the D&D Beyond output, then rewrites the resulting tags. Unknown spells
+// are reported as warnings by the converter.
+func convertDescription(description string, spells map[string]bool, rewriter tagRewriter) (string, []string, error) {
+	text, warnings, err := formatter.FormatAbility(parser.Ability{RollText: description}, spells)
+	if err != nil {
+		return "", warnings, err
+	}
+
+	var b strings.Builder
+	last := 0
+
+	for _, match := range ddbTagRegex.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(rewriter.text(text[last:match[0]]))
+		last = match[1]
+
+		if match[6] >= 0 {
+			spellName := text[match[6]:match[7]]
+			b.WriteString(rewriter.spell(spellName, converter.IsValidSpell(spellName, spells)))
+			continue
+		}
+
+		var data converter.RollableData
+		if err := json.Unmarshal([]byte(text[match[4]:match[5]]), &data); err != nil {
+			b.WriteString(rewriter.text(text[match[0]:match[1]]))
+			continue
+		}
+		b.WriteString(rewriter.roll(text[match[2]:match[3]], data))
+	}
+
+	b.WriteString(rewriter.text(text[last:]))
+	return b.String(), warnings, nil
+}
+
+// DamageTypes returns the damage type written after each valid damage roll
+// in a description, in the same order as ExtractRollables returns them, in
+// lower case. Rolls without a recognised type get an empty string.
+func DamageTypes(description string) []string {
+	var damageTypes []string
+	for _, match := range damageTypeRegex.FindAllStringSubmatch(description, -1) {
+		if _, err := converter.ParseDiceNotation(match[1]); err != nil {
+			continue
+		}
+		damageTypes = append(damageTypes, strings.ToLower(match[2]))
+	}
+	return damageTypes
+}
+
+// encodeJSON encodes an export as indented JSON. HTML and macro characters
+// are left unescaped so the file stays readable.
+func encodeJSON(v any) (string, error) {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
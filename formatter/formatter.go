@@ -3,9 +3,19 @@ package formatter
 import (
 	"character-tool/converter"
 	"character-tool/parser"
+	"fmt"
 	"strings"
+	"text/template"
 )
 
+// AbilityTemplate joins an ability's title (its name and usage) and
+// description. It is the built-in "ability" template: FormatAbility runs it
+// on the raw ability, and output templates can call it on converted abilities.
+const AbilityTemplate = `{{if .Name}}{{.Title}}. {{end}}{{.Description}}`
+
+// abilityTemplate is AbilityTemplate, parsed once
+var abilityTemplate = template.Must(template.New("ability").Parse(AbilityTemplate))
+
 // FormatAbilities formats a list of abilities with dice rolls and spell links converted
 func FormatAbilities(abilities []parser.Ability, spells map[string]bool) (string, []string, error) {
 	if len(abilities) == 0 {
@@ -16,21 +26,8 @@ func FormatAbilities(abilities []parser.Ability, spells map[string]bool) (string
 	var allWarnings []string
 
 	for _, ability := range abilities {
-		var text string
-		if ability.Name != "" {
-			// Named ability: format as "Name. Description"
-			text = ability.Name + ". " + ability.Description
-		} else {
-			// Plain text paragraph: just the description
-			text = ability.Description
-		}
-
-		// Convert spell links first
-		text, spellWarnings := converter.ConvertSpellLinks(text, spells)
-		allWarnings = append(allWarnings, spellWarnings...)
-
-		// Convert dice rolls (use ability name as action name, or empty string for plain text)
-		text, err := converter.ConvertDiceRolls(text, ability.Name)
+		text, warnings, err := FormatAbility(ability, spells)
+		allWarnings = append(allWarnings, warnings...)
 		if err != nil {
 			return "", allWarnings, err
 		}
@@ -43,3 +40,112 @@ func FormatAbilities(abilities []parser.Ability, spells map[string]bool) (string
 
 	return result, allWarnings, nil
 }
+
+// FormatAbility formats a single ability as "Name (Usage). Description" with
+// dice rolls, recharge rolls and spell links converted
+func FormatAbility(ability parser.Ability, spells map[string]bool) (string, []string, error) {
+	// Named abilities become "Name. Description", plain text paragraphs
+	// just the description. The template writes the text with its rolls as
+	// keywords.
+	ability.Description = ability.RollText
+	var b strings.Builder
+	if err := abilityTemplate.Execute(&b, ability); err != nil {
+		return "", nil, err
+	}
+
+	// Use ability name as action name, or empty string for plain text
+	text, warnings, err := convertText(b.String(), ability.Name, spells)
+	if err != nil {
+		return "", warnings, err
+	}
+	return converter.ConvertRecharge(text, ability.Name), warnings, nil
+}
+
+// convertText converts spell links and dice rolls in text
+func convertText(text string, actionName string, spells map[string]bool) (string, []string, error) {
+	// Convert spell links first
+	text, warnings := converter.ConvertSpellLinks(text, spells)
+
+	// Convert dice rolls
+	text, err := converter.ConvertDiceRolls(text, actionName)
+	if err != nil {
+		return "", warnings, err
+	}
+
+	return text, warnings, nil
+}
+
+// StripMarkdown returns a copy of the parse result with inline markdown
+// removed from every name and description. The parser keeps descriptions as
+// written, so this is applied before formatting D&D Beyond text, which shows
+// markdown literally, unless --keep-markdown is set.
+func StripMarkdown(result *parser.ParseResult) *parser.ParseResult {
+	strip := func(abilities []parser.Ability) []parser.Ability {
+		stripped := make([]parser.Ability, 0, len(abilities))
+		for _, ability := range abilities {
+			stripped = append(stripped, StripAbility(ability))
+		}
+		return stripped
+	}
+
+	return &parser.ParseResult{
+		Traits:       strip(result.Traits),
+		Actions:      strip(result.Actions),
+		BonusActions: strip(result.BonusActions),
+		Reactions:    strip(result.Reactions),
+		Warnings:     result.Warnings,
+		Ruleset:      result.Ruleset,
+	}
+}
+
+// StripAbility returns the ability with inline markdown removed from its name
+// and description
+func StripAbility(ability parser.Ability) parser.Ability {
+	ability.Name = converter.StripMarkdown(ability.Name)
+	ability.Description = converter.StripMarkdown(ability.Description)
+	ability.RollText = converter.StripMarkdown(ability.RollText)
+	return ability
+}
+
+// FormattedSection holds the D&D Beyond text for one section
+type FormattedSection struct {
+	Type      parser.AbilityType
+	Text      string
+	Abilities int
+	Warnings  []string
+}
+
+// FormatSections formats every non-empty section of a parse result in output order
+func FormatSections(result *parser.ParseResult, spells map[string]bool) ([]FormattedSection, error) {
+	var formatted []FormattedSection
+
+	for _, section := range result.Sections() {
+		if len(section.Abilities) == 0 {
+			continue
+		}
+
+		text, warnings, err := FormatAbilities(section.Abilities, spells)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", section.Type.SectionName(), err)
+		}
+
+		formatted = append(formatted, FormattedSection{
+			Type:      section.Type,
+			Text:      text,
+			Abilities: len(section.Abilities),
+			Warnings:  warnings,
+		})
+	}
+
+	return formatted, nil
+}
+
+// CombineSections joins formatted sections into a single document, each
+// section introduced by a "## Name" heading
+func CombineSections(sections []FormattedSection) string {
+	var parts []string
+	for _, section := range sections {
+		parts = append(parts, "## "+section.Type.SectionName()+"\n\n"+section.Text)
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -30,6 +30,7 @@ func TestFormatAbilities_SingleAbility(t *testing.T) {
 		{
 			Name:        "Darkvision",
 			Description: "You can see in dim light within 60 feet.",
+			RollText:    "You can see in dim light within 60 feet.",
 			Type:        parser.Trait,
 		},
 	}
@@ -56,11 +57,13 @@ func TestFormatAbilities_MultipleAbilities(t *testing.T) {
 		{
 			Name:        "Darkvision",
 			Description: "You can see in dim light within 60 feet.",
+			RollText:    "You can see in dim light within 60 feet.",
 			Type:        parser.Trait,
 		},
 		{
 			Name:        "Pack Tactics",
 			Description: "You have advantage on attack rolls.",
+			RollText:    "You have advantage on attack rolls.",
 			Type:        parser.Trait,
 		},
 	}
@@ -88,6 +91,7 @@ func TestFormatAbilities_WithDiceRolls(t *testing.T) {
 		{
 			Name:        "Quarterstaff",
 			Description: "Melee Weapon Attack: to hit: 1d20+2, reach 5 ft. Hit: damage: 1d6+2 bludgeoning.",
+			RollText:    "Melee Weapon Attack: to hit: 1d20+2, reach 5 ft. Hit: damage: 1d6+2 bludgeoning.",
 			Type:        parser.Action,
 		},
 	}
@@ -122,6 +126,7 @@ func TestFormatAbilities_WithSpellLinks(t *testing.T) {
 		{
 			Name:        "Spellcasting",
 			Description: "You can cast {{spell:Fireball}} and {{spell:Shield}}.",
+			RollText:    "You can cast {{spell:Fireball}} and {{spell:Shield}}.",
 			Type:        parser.Trait,
 		},
 	}
@@ -155,6 +160,7 @@ func TestFormatAbilities_WithInvalidSpell(t *testing.T) {
 		{
 			Name:        "Spellcasting",
 			Description: "You can cast {{spell:NotASpell}}.",
+			RollText:    "You can cast {{spell:NotASpell}}.",
 			Type:        parser.Trait,
 		},
 	}
@@ -186,6 +192,7 @@ func TestFormatAbilities_WithDiceAndSpells(t *testing.T) {
 		{
 			Name:        "Magic Attack",
 			Description: "Cast {{spell:Fire Bolt}} for to hit: 1d20+5, dealing damage: 1d10 fire damage.",
+			RollText:    "Cast {{spell:Fire Bolt}} for to hit: 1d20+5, dealing damage: 1d10 fire damage.",
 			Type:        parser.Action,
 		},
 	}
@@ -218,11 +225,13 @@ func TestFormatAbilities_D20VsDamageDisplay(t *testing.T) {
 		{
 			Name:        "Longsword",
 			Description: "Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 1d8+3 slashing.",
+			RollText:    "Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 1d8+3 slashing.",
 			Type:        parser.Action,
 		},
 		{
 			Name:        "Dagger",
 			Description: "Melee or Ranged Weapon Attack: to hit: 1d20+3, reach 5 ft. Hit: damage: 1d4+3 piercing.",
+			RollText:    "Melee or Ranged Weapon Attack: to hit: 1d20+3, reach 5 ft. Hit: damage: 1d4+3 piercing.",
 			Type:        parser.Action,
 		},
 	}
@@ -260,16 +269,19 @@ func TestFormatAbilities_PlainTextParagraphs(t *testing.T) {
 		{
 			Name:        "",
 			Description: "This character has enhanced abilities due to their training.",
+			RollText:    "This character has enhanced abilities due to their training.",
 			Type:        parser.Trait,
 		},
 		{
 			Name:        "Enhanced Reflexes",
 			Description: "You gain a +2 bonus to AC.",
+			RollText:    "You gain a +2 bonus to AC.",
 			Type:        parser.Trait,
 		},
 		{
 			Name:        "",
 			Description: "The following abilities are granted by their magical armor.",
+			RollText:    "The following abilities are granted by their magical armor.",
 			Type:        parser.Trait,
 		},
 	}
@@ -312,3 +324,94 @@ func TestFormatAbilities_PlainTextParagraphs(t *testing.T) {
 		t.Errorf("Expected no warnings, got %v", warnings)
 	}
 }
+
+func TestFormatAbility_Usage(t *testing.T) {
+	ability := parser.Ability{Name: "Fire Breath", Description: "damage: 6d6 fire damage.", RollText: "damage: 6d6 fire damage.", Type: parser.Action, Usage: parser.Usage{Recharge: 5, Uses: 1, Per: "Day"}}
+
+	text, _, err := FormatAbility(ability, map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Fire Breath ([rollable]Recharge 5–6;{"diceNotation":"1d6","rollType":"recharge","rollAction":"Fire Breath"}[/rollable]; 1/Day). ` +
+		`[rollable]21(6d6);{"diceNotation":"6d6","rollType":"damage","rollAction":"Fire Breath"}[/rollable] fire damage.`
+	if text != expected {
+		t.Errorf("Expected %q, got %q", expected, text)
+	}
+}
+
+func TestFormatSections_OrderAndSkipsEmpty(t *testing.T) {
+	result := &parser.ParseResult{
+		Traits: []parser.Ability{
+			{Name: "Darkvision", Description: "You can see in the dark.", RollText: "You can see in the dark.", Type: parser.Trait},
+		},
+		Reactions: []parser.Ability{
+			{Name: "Shield", Description: "Cast {{spell:Shield}}.", RollText: "Cast {{spell:Shield}}.", Type: parser.Reaction},
+		},
+	}
+	spells := map[string]bool{}
+
+	sections, err := FormatSections(result, spells)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(sections))
+	}
+	if sections[0].Type != parser.Trait || sections[1].Type != parser.Reaction {
+		t.Errorf("Expected Traits then Reactions, got %v then %v", sections[0].Type, sections[1].Type)
+	}
+	if sections[1].Abilities != 1 {
+		t.Errorf("Expected 1 reaction, got %d", sections[1].Abilities)
+	}
+	if len(sections[1].Warnings) != 1 {
+		t.Errorf("Expected 1 warning for unknown spell, got %v", sections[1].Warnings)
+	}
+}
+
+func TestCombineSections(t *testing.T) {
+	sections := []FormattedSection{
+		{Type: parser.Trait, Text: "Darkvision. You can see in the dark."},
+		{Type: parser.BonusAction, Text: "Dash. Move."},
+	}
+
+	expected := "## Traits\n\nDarkvision. You can see in the dark.\n\n## Bonus Actions\n\nDash. Move."
+	if result := CombineSections(sections); result != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, result)
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	result := &parser.ParseResult{
+		Traits: []parser.Ability{
+			{Name: "*Keen* Senses", Description: "You have **advantage** on [Perception](rules.md) checks.", RollText: "You have **advantage** on [Perception](rules.md) checks.", Type: parser.Trait},
+		},
+		Actions: []parser.Ability{
+			{Name: "Longsword", Description: "to hit: 1d20+5, damage: 1d8+3 _slashing_ damage.", RollText: "to hit: 1d20+5, damage: 1d8+3 _slashing_ damage.", Type: parser.Action},
+		},
+	}
+
+	stripped := StripMarkdown(result)
+
+	if trait := stripped.Traits[0]; trait.Name != "Keen Senses" || trait.Description != "You have advantage on Perception checks." {
+		t.Errorf("Expected the trait without markdown, got %+v", trait)
+	}
+	if stripped.Actions[0].Type != parser.Action {
+		t.Errorf("Expected the ability type to be kept, got %v", stripped.Actions[0].Type)
+	}
+	if result.Traits[0].Name != "*Keen* Senses" {
+		t.Errorf("Expected the original result to be unchanged, got %q", result.Traits[0].Name)
+	}
+	if len(stripped.BonusActions) != 0 || stripped.BonusActions == nil {
+		t.Errorf("Expected empty bonus actions, got %v", stripped.BonusActions)
+	}
+
+	text, _, err := FormatAbilities(stripped.Actions, map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, `[/rollable] slashing damage.`) {
+		t.Errorf("Expected the rolls to convert after stripping, got %q", text)
+	}
+}
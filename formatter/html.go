@@ -0,0 +1,264 @@
+package formatter
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"encoding/json"
+	"html/template"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Pre-compiled patterns for the tags produced by the converters
+var (
+	ddbTagRegex   = regexp.MustCompile(`\[rollable\]([^;\[]*);(\{.*?\})\[/rollable\]|\[spell\](.*?)\[/spell\]`)
+	spellSlugTrim = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// HTMLAbility is an ability prepared for the preview template
+type HTMLAbility struct {
+	Name string
+	// Body is the first paragraph, shown after the name
+	Body template.HTML
+	// More holds the paragraph and list elements that follow it
+	More []template.HTML
+}
+
+// HTMLSection is a section prepared for the preview template
+type HTMLSection struct {
+	Name      string
+	Slug      string
+	Abilities []HTMLAbility
+}
+
+// HTMLPage is the data passed to the preview template
+type HTMLPage struct {
+	Title    string
+	Sections []HTMLSection
+	// Script is optional JavaScript added to the page, used by the preview server
+	Script template.JS
+}
+
+// FormatHTML renders a standalone HTML page previewing the D&D Beyond output
+// as a stat block: spell tags become links and rollables become buttons
+func FormatHTML(result *parser.ParseResult, spells map[string]bool, title string) (string, []string, error) {
+	sections, warnings, err := BuildHTMLSections(result, spells)
+	if err != nil {
+		return "", warnings, err
+	}
+
+	page, err := RenderHTMLPage(HTMLPage{Title: title, Sections: sections})
+	if err != nil {
+		return "", warnings, err
+	}
+
+	return page, warnings, nil
+}
+
+// RenderHTMLPage renders prepared sections into the stat-block page
+func RenderHTMLPage(page HTMLPage) (string, error) {
+	var b strings.Builder
+	if err := previewTemplate.Execute(&b, page); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// BuildHTMLSections converts every non-empty section into HTML fragments
+func BuildHTMLSections(result *parser.ParseResult, spells map[string]bool) ([]HTMLSection, []string, error) {
+	var sections []HTMLSection
+	var allWarnings []string
+
+	for _, section := range result.Sections() {
+		if len(section.Abilities) == 0 {
+			continue
+		}
+
+		htmlSection := HTMLSection{
+			Name: section.Type.SectionName(),
+			Slug: section.Type.Slug(),
+		}
+
+		for _, ability := range section.Abilities {
+			htmlAbility, warnings, err := buildHTMLAbility(ability, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return nil, allWarnings, err
+			}
+			htmlSection.Abilities = append(htmlSection.Abilities, htmlAbility)
+		}
+
+		sections = append(sections, htmlSection)
+	}
+
+	return sections, allWarnings, nil
+}
+
+// buildHTMLAbility converts an ability's description block by block: the
+// first paragraph follows the name, and later paragraphs and lists become
+// elements of their own
+func buildHTMLAbility(ability parser.Ability, spells map[string]bool) (HTMLAbility, []string, error) {
+	htmlAbility := HTMLAbility{Name: ability.Title()}
+	var allWarnings []string
+
+	convert := func(text string) (string, error) {
+		converted, warnings, err := convertText(text, ability.Name, spells)
+		allWarnings = append(allWarnings, warnings...)
+		return string(DDBToHTML(converted)), err
+	}
+
+	for i, block := range parser.Blocks(ability.RollText) {
+		if len(block.Items) == 0 {
+			body, err := convert(block.Text)
+			if err != nil {
+				return htmlAbility, allWarnings, err
+			}
+			if i == 0 {
+				htmlAbility.Body = template.HTML(body)
+				continue
+			}
+			htmlAbility.More = append(htmlAbility.More, template.HTML(`<p class="ability">`+body+`</p>`))
+			continue
+		}
+
+		tag := "ul"
+		if block.Ordered {
+			tag = "ol"
+		}
+		var b strings.Builder
+		b.WriteString(`<` + tag + ` class="ability">`)
+		for _, item := range block.Items {
+			body, err := convert(item)
+			if err != nil {
+				return htmlAbility, allWarnings, err
+			}
+			b.WriteString(`<li>` + body + `</li>`)
+		}
+		b.WriteString(`</` + tag + `>`)
+		htmlAbility.More = append(htmlAbility.More, template.HTML(b.String()))
+	}
+
+	return htmlAbility, allWarnings, nil
+}
+
+// DDBToHTML converts D&D Beyond formatted text to HTML. Spell tags become
+// links to D&D Beyond, rollables become buttons carrying the roll data, and
+// all other text is escaped.
+func DDBToHTML(text string) template.HTML {
+	var b strings.Builder
+	last := 0
+
+	for _, match := range ddbTagRegex.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(template.HTMLEscapeString(text[last:match[0]]))
+		last = match[1]
+
+		if match[6] >= 0 {
+			spellName := text[match[6]:match[7]]
+			b.WriteString(`<a class="spell" href="` + template.HTMLEscapeString(spellURL(spellName)) + `">`)
+			b.WriteString(template.HTMLEscapeString(spellName))
+			b.WriteString(`</a>`)
+			continue
+		}
+
+		display := text[match[2]:match[3]]
+		var data converter.RollableData
+		if err := json.Unmarshal([]byte(text[match[4]:match[5]]), &data); err != nil {
+			// Not a tag we produced: show it as-is
+			b.WriteString(template.HTMLEscapeString(text[match[0]:match[1]]))
+			continue
+		}
+		b.WriteString(rollableButton(display, data))
+	}
+
+	b.WriteString(template.HTMLEscapeString(text[last:]))
+	return template.HTML(b.String())
+}
+
+// rollableButton renders a rollable as a button showing its display value and notation
+func rollableButton(display string, data converter.RollableData) string {
+	var b strings.Builder
+	b.WriteString(`<button type="button" class="rollable"`)
+	b.WriteString(` data-notation="` + template.HTMLEscapeString(data.DiceNotation) + `"`)
+	b.WriteString(` data-roll-type="` + template.HTMLEscapeString(data.RollType) + `"`)
+	b.WriteString(` data-roll-action="` + template.HTMLEscapeString(data.RollAction) + `"`)
+	b.WriteString(` title="` + template.HTMLEscapeString(data.RollType+": "+data.DiceNotation) + `">`)
+
+	// d20 rolls display only the modifier, so show the notation alongside it
+	if display == "" {
+		display = data.DiceNotation
+	}
+	b.WriteString(`<span class="display">` + template.HTMLEscapeString(display) + `</span>`)
+	if !strings.Contains(display, data.DiceNotation) {
+		b.WriteString(` <span class="notation">` + template.HTMLEscapeString(data.DiceNotation) + `</span>`)
+	}
+
+	b.WriteString(`</button>`)
+	return b.String()
+}
+
+// DisplayTitle turns an input file path into a human-readable page title
+// (e.g. "monsters/young-red_dragon.md" → "young red dragon")
+func DisplayTitle(path string) string {
+	base := filepath.Base(path)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(base)
+}
+
+// spellURL returns the D&D Beyond page for a spell name
+func spellURL(spellName string) string {
+	slug := strings.ToLower(strings.ReplaceAll(spellName, "'", ""))
+	slug = strings.Trim(spellSlugTrim.ReplaceAllString(slug, "-"), "-")
+	return "https://www.dndbeyond.com/spells/" + slug
+}
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { background: #f4f1ea; margin: 2rem; font-family: "Scala Sans", "Helvetica Neue", Arial, sans-serif; }
+.stat-block { max-width: 42rem; margin: 0 auto; padding: 1rem 1.5rem; background: #fdf1dc; border-top: 5px solid #e69a28; border-bottom: 5px solid #e69a28; box-shadow: 0 0 1.5rem #867453; }
+.stat-block h1 { margin: 0; color: #7a200d; font-family: "Mrs Eaves", "Libre Baskerville", Georgia, serif; font-variant: small-caps; font-size: 1.8rem; }
+.stat-block h2 { margin: 1rem 0 0.4rem; color: #7a200d; font-family: "Mrs Eaves", "Libre Baskerville", Georgia, serif; font-variant: small-caps; font-weight: normal; font-size: 1.3rem; border-bottom: 1px solid #7a200d; }
+.rule { height: 5px; border: 0; background: linear-gradient(10deg, #922610, #fdf1dc); }
+.ability { margin: 0.4rem 0; line-height: 1.4; }
+.ability-name { font-weight: bold; font-style: italic; }
+ul.ability, ol.ability { padding-left: 1.5rem; }
+a.spell { color: #7a200d; font-style: italic; }
+button.rollable { font: inherit; padding: 0 0.35rem; border: 1px solid #7a200d; border-radius: 3px; background: #fff; color: #7a200d; cursor: pointer; }
+button.rollable .notation { font-size: 0.8em; color: #867453; }
+#roll-log { margin-top: 1rem; padding-top: 0.5rem; border-top: 1px solid #7a200d; font-size: 0.9rem; color: #58180d; }
+</style>
+</head>
+<body>
+<div class="stat-block">
+<h1>{{.Title}}</h1>
+<hr class="rule">
+{{- range .Sections}}
+<section class="{{.Slug}}">
+<h2>{{.Name}}</h2>
+{{- range .Abilities}}
+{{- if or .Name .Body}}
+<p class="ability">{{if .Name}}<span class="ability-name">{{.Name}}.</span> {{end}}{{.Body}}</p>
+{{- end}}
+{{- range .More}}
+{{.}}
+{{- end}}
+{{- end}}
+</section>
+{{- end}}
+{{- if .Script}}
+<div id="roll-log"></div>
+{{- end}}
+</div>
+{{- if .Script}}
+<script>
+{{.Script}}
+</script>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,119 @@
+package formatter
+
+import (
+	"character-tool/parser"
+	"strings"
+	"testing"
+)
+
+func TestDDBToHTML_Rollables(t *testing.T) {
+	text := `Attack: [rollable]+5;{"diceNotation":"1d20+5","rollType":"to hit","rollAction":"Longsword"}[/rollable]. ` +
+		`Hit: [rollable]8(1d8+3);{"diceNotation":"1d8+3","rollType":"damage","rollAction":"Longsword"}[/rollable].`
+
+	result := string(DDBToHTML(text))
+
+	expected := []string{
+		`<button type="button" class="rollable" data-notation="1d20+5" data-roll-type="to hit" data-roll-action="Longsword" title="to hit: 1d20+5">`,
+		`<span class="display">+5</span> <span class="notation">1d20+5</span></button>`,
+		`<span class="display">8(1d8+3)</span></button>`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result, exp) {
+			t.Errorf("Expected output to contain:\n%s\nGot:\n%s", exp, result)
+		}
+	}
+	if strings.Contains(result, "[rollable]") {
+		t.Errorf("Expected rollable tags to be replaced, got %s", result)
+	}
+}
+
+func TestDDBToHTML_D20WithoutModifier(t *testing.T) {
+	text := `[rollable];{"diceNotation":"1d20","rollType":"save","rollAction":"Resist"}[/rollable]`
+
+	result := string(DDBToHTML(text))
+
+	if !strings.Contains(result, `<span class="display">1d20</span></button>`) {
+		t.Errorf("Expected notation shown for bare d20, got %s", result)
+	}
+}
+
+func TestDDBToHTML_SpellsAndEscaping(t *testing.T) {
+	text := `Cast [spell]Bigby's Hand[/spell] & <b>shout</b>.`
+
+	result := string(DDBToHTML(text))
+
+	expected := `Cast <a class="spell" href="https://www.dndbeyond.com/spells/bigbys-hand">Bigby&#39;s Hand</a> &amp; &lt;b&gt;shout&lt;/b&gt;.`
+	if result != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, result)
+	}
+}
+
+func TestFormatHTML_StatBlock(t *testing.T) {
+	result := &parser.ParseResult{
+		Traits: []parser.Ability{
+			{Name: "", Description: "A seasoned <veteran>.", RollText: "A seasoned <veteran>.", Type: parser.Trait},
+		},
+		Actions: []parser.Ability{
+			{Name: "Fire Bolt", Description: "to hit: 1d20+5. Hit: damage: 1d10 fire. {{spell:Fire Bolt}}", RollText: "to hit: 1d20+5. Hit: damage: 1d10 fire. {{spell:Fire Bolt}}", Type: parser.Action},
+		},
+	}
+	spells := map[string]bool{}
+
+	page, warnings, err := FormatHTML(result, spells, "Wizard & Co")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"<!DOCTYPE html>",
+		"<title>Wizard &amp; Co</title>",
+		`<section class="traits">`,
+		`<p class="ability">A seasoned &lt;veteran&gt;.</p>`,
+		`<h2>Actions</h2>`,
+		`<span class="ability-name">Fire Bolt.</span>`,
+		`data-roll-action="Fire Bolt"`,
+		`<span class="display">6(1d10)</span>`,
+		`href="https://www.dndbeyond.com/spells/fire-bolt"`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(page, exp) {
+			t.Errorf("Expected page to contain %q", exp)
+		}
+	}
+	if strings.Contains(page, "<h2>Bonus Actions</h2>") {
+		t.Error("Expected empty sections to be omitted")
+	}
+
+	if len(warnings) != 1 {
+		t.Errorf("Expected 1 warning for unknown spell, got %v", warnings)
+	}
+}
+
+func TestDisplayTitle(t *testing.T) {
+	if got := DisplayTitle("monsters/young-red_dragon.md"); got != "young red dragon" {
+		t.Errorf("Expected 'young red dragon', got %q", got)
+	}
+}
+
+func TestFormatHTML_ParagraphsAndLists(t *testing.T) {
+	result := &parser.ParseResult{
+		Actions: []parser.Ability{
+			{Name: "Wild Shape", Description: "You become a beast.\n\nIt lasts an hour.\n\n- Wolf\n- Bear: damage: 1d8+4\n\n1. First", RollText: "You become a beast.\n\nIt lasts an hour.\n\n- Wolf\n- Bear: damage: 1d8+4\n\n1. First", Type: parser.Action},
+		},
+	}
+
+	page, _, err := FormatHTML(result, map[string]bool{}, "druid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<p class="ability"><span class="ability-name">Wild Shape.</span> You become a beast.</p>
+<p class="ability">It lasts an hour.</p>
+<ul class="ability"><li>Wolf</li><li>Bear: <button type="button" class="rollable" data-notation="1d8+4"`
+	if !strings.Contains(page, expected) {
+		t.Errorf("Expected paragraphs and a list, got:\n%s", page)
+	}
+	if !strings.Contains(page, `<ol class="ability"><li>First</li></ol>`) {
+		t.Errorf("Expected a numbered list, got:\n%s", page)
+	}
+}
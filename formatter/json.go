@@ -0,0 +1,224 @@
+package formatter
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"encoding/json"
+	"strings"
+)
+
+// JSONSchemaVersion is the version of the JSON output schema documented in
+// docs/JSON_SCHEMA.md. It is incremented whenever a field is removed, renamed
+// or changes meaning; adding new fields does not change the version.
+const JSONSchemaVersion = 1
+
+// JSONDocument is the top-level JSON output for one character
+type JSONDocument struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Source        string           `json:"source,omitempty"`
+	Sections      []JSONSection    `json:"sections"`
+	Diagnostics   []JSONDiagnostic `json:"diagnostics"`
+}
+
+// JSONSection holds the abilities of one section
+type JSONSection struct {
+	Name      string        `json:"name"`
+	Slug      string        `json:"slug"`
+	Abilities []JSONAbility `json:"abilities"`
+}
+
+// JSONAbility is a single parsed and converted ability
+type JSONAbility struct {
+	Name        string         `json:"name"`
+	Type        string         `json:"type"`
+	Description string         `json:"description"`
+	Text        string         `json:"text"`
+	Usage       *JSONUsage     `json:"usage,omitempty"`
+	Attack      *JSONAttack    `json:"attack,omitempty"`
+	Rollables   []JSONRollable `json:"rollables"`
+	Spells      []JSONSpell    `json:"spells"`
+}
+
+// JSONUsage holds the usage limits split off an ability's name
+type JSONUsage struct {
+	Text     string `json:"text"`
+	Recharge int    `json:"recharge,omitempty"`
+	Uses     int    `json:"uses,omitempty"`
+	Per      string `json:"per,omitempty"`
+	Cost     int    `json:"cost,omitempty"`
+	Resource string `json:"resource,omitempty"`
+}
+
+// JSONAttack is the attack stat line read from an ability's description
+type JSONAttack struct {
+	Kind      string           `json:"kind"`
+	Melee     bool             `json:"melee"`
+	Ranged    bool             `json:"ranged"`
+	Spell     bool             `json:"spell"`
+	Bonus     string           `json:"bonus,omitempty"`
+	Reach     int              `json:"reach,omitempty"`
+	Range     int              `json:"range,omitempty"`
+	LongRange int              `json:"longRange,omitempty"`
+	Targets   string           `json:"targets,omitempty"`
+	Damage    []JSONDamagePart `json:"damage"`
+	Versatile string           `json:"versatile,omitempty"`
+	Riders    []string         `json:"riders"`
+}
+
+// JSONDamagePart is one damage roll of an attack
+type JSONDamagePart struct {
+	Notation string `json:"notation"`
+	Type     string `json:"type,omitempty"`
+}
+
+// JSONRollable describes one dice roll extracted from an ability
+type JSONRollable struct {
+	converter.RollableData
+	Display string `json:"display"`
+	Average int    `json:"average"`
+}
+
+// JSONSpell is a spell referenced by an ability
+type JSONSpell struct {
+	Name  string `json:"name"`
+	Known bool   `json:"known"`
+}
+
+// JSONDiagnostic is a warning raised while converting an ability, or a note
+// about a roll read with --infer-rolls
+type JSONDiagnostic struct {
+	Severity   string `json:"severity"`
+	Section    string `json:"section"`
+	Ability    string `json:"ability,omitempty"`
+	Message    string `json:"message"`
+	Confidence string `json:"confidence,omitempty"`
+}
+
+// BuildJSONDocument converts a parse result into the JSON output structure.
+// All four sections are always present so consumers can rely on the shape.
+// Descriptions are kept as written; the D&D Beyond text has its inline
+// markdown stripped unless keepMarkdown is set, like the .txt files.
+func BuildJSONDocument(result *parser.ParseResult, spells map[string]bool, keepMarkdown bool) (*JSONDocument, error) {
+	doc := &JSONDocument{
+		SchemaVersion: JSONSchemaVersion,
+		Sections:      []JSONSection{},
+		Diagnostics:   []JSONDiagnostic{},
+	}
+
+	for _, warning := range result.Warnings {
+		diagnostic := JSONDiagnostic{
+			Severity: "warning",
+			Section:  warning.Type.Slug(),
+			Ability:  warning.Ability,
+			Message:  warning.Message,
+		}
+		if warning.Confidence != 0 {
+			diagnostic.Severity, diagnostic.Confidence = "info", warning.Confidence.String()
+		}
+		doc.Diagnostics = append(doc.Diagnostics, diagnostic)
+	}
+
+	for _, section := range result.Sections() {
+		jsonSection := JSONSection{
+			Name:      section.Type.SectionName(),
+			Slug:      section.Type.Slug(),
+			Abilities: []JSONAbility{},
+		}
+
+		for _, ability := range section.Abilities {
+			ddbAbility := ability
+			if !keepMarkdown {
+				ddbAbility = StripAbility(ability)
+			}
+			text, warnings, err := FormatAbility(ddbAbility, spells)
+			if err != nil {
+				return nil, err
+			}
+
+			jsonAbility := JSONAbility{
+				Name:        ability.Name,
+				Type:        strings.ReplaceAll(strings.ToLower(ability.Type.String()), " ", "-"),
+				Description: ability.Description,
+				Text:        text,
+				Rollables:   []JSONRollable{},
+				Spells:      []JSONSpell{},
+			}
+			if usage := ability.Usage; !usage.IsZero() {
+				jsonAbility.Usage = &JSONUsage{
+					Text:     usage.String(),
+					Recharge: usage.Recharge,
+					Uses:     usage.Uses,
+					Per:      usage.Per,
+					Cost:     usage.Cost,
+					Resource: usage.Resource,
+				}
+			}
+
+			if ability.Attack != nil {
+				jsonAbility.Attack = jsonAttack(ability.Attack)
+			}
+
+			for _, rollable := range converter.ExtractRollables(ability.RollText, ability.Name) {
+				jsonAbility.Rollables = append(jsonAbility.Rollables, JSONRollable{
+					RollableData: rollable,
+					Display:      converter.DisplayValue(rollable.DiceNotation),
+					Average:      converter.Average(rollable.DiceNotation),
+				})
+			}
+
+			for _, reference := range converter.ExtractSpellReferences(ability.RollText, spells) {
+				jsonAbility.Spells = append(jsonAbility.Spells, JSONSpell{
+					Name:  reference.SpellName,
+					Known: reference.IsValid,
+				})
+			}
+
+			for _, warning := range warnings {
+				doc.Diagnostics = append(doc.Diagnostics, JSONDiagnostic{
+					Severity: "warning",
+					Section:  jsonSection.Slug,
+					Ability:  ability.Name,
+					Message:  warning,
+				})
+			}
+
+			jsonSection.Abilities = append(jsonSection.Abilities, jsonAbility)
+		}
+
+		doc.Sections = append(doc.Sections, jsonSection)
+	}
+
+	return doc, nil
+}
+
+// jsonAttack converts a parsed attack to its JSON form
+func jsonAttack(attack *parser.Attack) *JSONAttack {
+	converted := &JSONAttack{
+		Kind:      attack.Kind(),
+		Melee:     attack.Melee,
+		Ranged:    attack.Ranged,
+		Spell:     attack.Spell,
+		Bonus:     attack.Bonus,
+		Reach:     attack.Reach,
+		Range:     attack.Range,
+		LongRange: attack.LongRange,
+		Targets:   attack.Targets,
+		Damage:    []JSONDamagePart{},
+		Versatile: attack.Versatile,
+		Riders:    []string{},
+	}
+	for _, part := range attack.Damage {
+		converted.Damage = append(converted.Damage, JSONDamagePart{Notation: part.Notation, Type: part.Type})
+	}
+	converted.Riders = append(converted.Riders, attack.Riders...)
+	return converted
+}
+
+// FormatJSON renders a JSON document as indented JSON
+func FormatJSON(doc *JSONDocument) (string, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
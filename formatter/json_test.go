@@ -0,0 +1,153 @@
+package formatter
+
+import (
+	"character-tool/parser"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildJSONDocument(t *testing.T) {
+	result := &parser.ParseResult{
+		Actions: []parser.Ability{
+			{
+				Name:        "Longsword",
+				Description: "Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing.",
+				RollText:    "Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing.",
+				Type:        parser.Action,
+			},
+		},
+		Reactions: []parser.Ability{
+			{
+				Name:        "Shield",
+				Description: "Cast {{spell:Shield}} or {{spell:Made Up}}.",
+				RollText:    "Cast {{spell:Shield}} or {{spell:Made Up}}.",
+				Type:        parser.Reaction,
+			},
+		},
+	}
+	spells := map[string]bool{"shield": true}
+
+	doc, err := BuildJSONDocument(result, spells, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if doc.SchemaVersion != JSONSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", JSONSchemaVersion, doc.SchemaVersion)
+	}
+	if len(doc.Sections) != 4 {
+		t.Fatalf("Expected all 4 sections, got %d", len(doc.Sections))
+	}
+	if len(doc.Sections[0].Abilities) != 0 {
+		t.Errorf("Expected empty traits, got %v", doc.Sections[0].Abilities)
+	}
+
+	longsword := doc.Sections[1].Abilities[0]
+	if longsword.Type != "action" {
+		t.Errorf("Expected type 'action', got %s", longsword.Type)
+	}
+	if longsword.Description != result.Actions[0].Description {
+		t.Errorf("Expected raw description, got %s", longsword.Description)
+	}
+	if !strings.HasPrefix(longsword.Text, "Longsword. Melee Weapon Attack: [rollable]+5;") {
+		t.Errorf("Expected converted text, got %s", longsword.Text)
+	}
+	if len(longsword.Rollables) != 2 {
+		t.Fatalf("Expected 2 rollables, got %d", len(longsword.Rollables))
+	}
+	damage := longsword.Rollables[1]
+	if damage.DiceNotation != "1d8+3" || damage.RollType != "damage" || damage.Display != "8(1d8+3)" || damage.Average != 8 {
+		t.Errorf("Unexpected damage rollable: %+v", damage)
+	}
+
+	shield := doc.Sections[3].Abilities[0]
+	if shield.Type != "reaction" {
+		t.Errorf("Expected type 'reaction', got %s", shield.Type)
+	}
+	expectedSpells := []JSONSpell{{Name: "Shield", Known: true}, {Name: "Made Up", Known: false}}
+	if len(shield.Spells) != 2 || shield.Spells[0] != expectedSpells[0] || shield.Spells[1] != expectedSpells[1] {
+		t.Errorf("Expected spells %v, got %v", expectedSpells, shield.Spells)
+	}
+
+	if len(doc.Diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %v", doc.Diagnostics)
+	}
+	diagnostic := doc.Diagnostics[0]
+	if diagnostic.Section != "reactions" || diagnostic.Ability != "Shield" || !strings.Contains(diagnostic.Message, "Made Up") {
+		t.Errorf("Unexpected diagnostic: %+v", diagnostic)
+	}
+}
+
+func TestBuildJSONDocument_InferredRolls(t *testing.T) {
+	result, err := parser.ParseMarkdownWith("## Actions\n\n**Bite.** The target takes 7 (2d6) piercing damage.", parser.Options{InferRolls: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := BuildJSONDocument(result, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := JSONDiagnostic{Severity: "info", Section: "actions", Ability: "Bite", Message: `read "7 (2d6) piercing damage" as damage: 2d6 (high confidence)`, Confidence: "high"}
+	if len(doc.Diagnostics) != 1 || doc.Diagnostics[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, doc.Diagnostics)
+	}
+	bite := doc.Sections[1].Abilities[0]
+	if len(bite.Rollables) != 1 || bite.Rollables[0].RollType != "damage" {
+		t.Errorf("Expected the inferred damage roll, got %+v", bite.Rollables)
+	}
+	if bite.Description != "The target takes 7 (2d6) piercing damage." {
+		t.Errorf("Expected the description as written, got %q", bite.Description)
+	}
+}
+
+func TestFormatJSON_FieldNames(t *testing.T) {
+	result := &parser.ParseResult{
+		Actions: []parser.Ability{
+			{Name: "Bite", Description: "to hit: 1d20+4", RollText: "to hit: 1d20+4", Type: parser.Action},
+		},
+	}
+
+	doc, err := BuildJSONDocument(result, map[string]bool{}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	doc.Source = "wolf.md"
+
+	output, err := FormatJSON(doc)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Decode generically so the test pins the documented field names
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(output), &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+
+	if decoded["schemaVersion"] != float64(1) || decoded["source"] != "wolf.md" {
+		t.Errorf("Unexpected top-level fields: %v", decoded)
+	}
+
+	sections := decoded["sections"].([]any)
+	actions := sections[1].(map[string]any)
+	if actions["slug"] != "actions" {
+		t.Errorf("Expected actions slug, got %v", actions["slug"])
+	}
+
+	bite := actions["abilities"].([]any)[0].(map[string]any)
+	for _, field := range []string{"name", "type", "description", "text", "rollables", "spells"} {
+		if _, ok := bite[field]; !ok {
+			t.Errorf("Expected ability field %q in %v", field, bite)
+		}
+	}
+
+	rollable := bite["rollables"].([]any)[0].(map[string]any)
+	for _, field := range []string{"diceNotation", "rollType", "rollAction", "display", "average"} {
+		if _, ok := rollable[field]; !ok {
+			t.Errorf("Expected rollable field %q in %v", field, rollable)
+		}
+	}
+}
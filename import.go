@@ -0,0 +1,167 @@
+package main
+
+import (
+	"character-tool/batch"
+	"character-tool/converter"
+	"character-tool/importer"
+	"character-tool/parser"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFrom    string
+	importForce   bool
+	importStdout  bool
+	importMonster string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Convert D&D Beyond text or SRD monster JSON into character markdown",
+	Long: `Parses text containing D&D Beyond [rollable] and [spell] tags, such as an
+existing homebrew entry or a file written by this tool, and writes it back out
+as character markdown:
+  - rollables become "to hit:", "damage:", "healing:" or "save:" keywords
+  - [spell]Name[/spell] becomes {{spell:Name}}
+  - "Name. Description" paragraphs become **Name.** Description
+  - section headings ("## Actions" or a line reading "Actions") start new sections
+
+Text before the first heading goes into the section named by --section, or the
+section the file is named after (actions.txt), or Traits. The markdown is
+written to <name>.md in the output directory; use --force to overwrite an
+existing file or --stdout to print it instead.
+
+With --from 5etools or --from open5e the input is a local 5etools bestiary or
+Open5e API JSON file. Each monster is written to its own file named after it
+(young-red-dragon.md); use --monster to import just one. Stat-block wording
+("+4 to hit", "5 (1d6 + 2) slashing damage") becomes roll keywords, spells
+become {{spell:}} markup, and legendary actions are added to Actions.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputs, err := collectInputs(args)
+		if err != nil {
+			return err
+		}
+		if len(inputs) != 1 {
+			return fmt.Errorf("import takes exactly one input file, got %d", len(inputs))
+		}
+		if importFrom != "ddb" && importFrom != "5etools" && importFrom != "open5e" {
+			return fmt.Errorf("unknown import format %q (must be ddb, 5etools or open5e)", importFrom)
+		}
+		sectionType, err := parseSectionFlag(section)
+		if err != nil {
+			return err
+		}
+
+		cmd.SilenceUsage = true
+		if importFrom != "ddb" {
+			return runImportMonsters(inputs[0], outputDir, importFrom, importMonster)
+		}
+		return runImport(inputs[0], outputDir, sectionType)
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFrom, "from", "ddb", "format of the input: ddb (D&D Beyond text), 5etools or open5e (monster JSON)")
+	importCmd.Flags().StringVar(&importMonster, "monster", "", "only import the monster with this name (5etools and open5e)")
+	importCmd.Flags().BoolVar(&importForce, "force", false, "overwrite an existing markdown file")
+	importCmd.Flags().BoolVar(&importStdout, "stdout", false, "print the markdown to stdout instead of writing a file")
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(inputFile, outputDir string, only *parser.AbilityType) error {
+	content, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	defaultSection := parser.Trait
+	if only != nil {
+		defaultSection = *only
+	} else if fromName, ok := parser.SectionType(batch.Stem(inputFile)); ok {
+		defaultSection = fromName
+	}
+
+	parsed, warnings := importer.ParseDDB(string(content), defaultSection)
+	return writeImport(parsed, warnings, filepath.Join(outputDir, batch.Stem(inputFile)+".md"))
+}
+
+func runImportMonsters(inputFile, outputDir, from, only string) error {
+	content, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	var monsters []importer.Monster
+	if from == "5etools" {
+		monsters, err = importer.ParseFiveTools(content)
+	} else {
+		spells, loadErr := converter.LoadSpells()
+		if loadErr != nil {
+			return fmt.Errorf("failed to load spell list: %w", loadErr)
+		}
+		monsters, err = importer.ParseOpen5e(content, spells)
+	}
+	if err != nil {
+		return err
+	}
+
+	if only != "" {
+		monsters = slices.DeleteFunc(monsters, func(monster importer.Monster) bool {
+			return !strings.EqualFold(monster.Name, only)
+		})
+		if len(monsters) == 0 {
+			return fmt.Errorf("no monster named %q in %s", only, inputFile)
+		}
+	}
+
+	for _, monster := range monsters {
+		if err := writeImport(monster.Result, monster.Warnings, filepath.Join(outputDir, monster.Slug()+".md")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeImport writes imported abilities as markdown, or prints them with --stdout
+func writeImport(parsed *parser.ParseResult, warnings []string, outputPath string) error {
+	markdown := importer.FormatMarkdown(parsed)
+
+	if importStdout {
+		fmt.Print(markdown)
+		printWarnings(os.Stderr, warnings, verbose)
+		return nil
+	}
+
+	if !importForce {
+		if _, err := os.Stat(outputPath); err == nil {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", outputPath)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, []byte(markdown), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+
+	abilities := 0
+	for _, section := range parsed.Sections() {
+		abilities += len(section.Abilities)
+	}
+	fmt.Printf("✓ Wrote %s (%d abilities)\n", outputPath, abilities)
+	printWarnings(os.Stdout, warnings, verbose)
+
+	return nil
+}
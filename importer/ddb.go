@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pre-compiled patterns for D&D Beyond tags and headings
+var (
+	rollableTagRegex = regexp.MustCompile(`\[rollable\]([^;\[]*);(\{.*?\})\[/rollable\]`)
+	spellTagRegex    = regexp.MustCompile(`\[spell\](.*?)\[/spell\]`)
+	headingRegex     = regexp.MustCompile(`^(?:#{1,6}\s+)?([A-Za-z][A-Za-z _-]*?):?\s*$`)
+)
+
+// rollTypeKeywords maps D&D Beyond roll types to the keywords ConvertDiceRolls
+// recognises. D&D Beyond's own entries sometimes use "heal" for healing.
+var rollTypeKeywords = map[string]string{
+	"to hit":  "to hit",
+	"damage":  "damage",
+	"healing": "healing",
+	"heal":    "healing",
+	"save":    "save",
+}
+
+// nameJoinWords may appear in lower case inside an ability name
+var nameJoinWords = map[string]bool{
+	"a": true, "an": true, "and": true, "at": true, "by": true, "for": true,
+	"from": true, "in": true, "of": true, "on": true, "or": true, "per": true,
+	"the": true, "to": true, "with": true,
+}
+
+// ParseDDB parses D&D Beyond formatted text (as produced by FormatAbilities)
+// back into abilities. Lines consisting of a section name, optionally as a
+// markdown heading, start a new section; text before any heading goes into
+// defaultSection. Returns warnings for tags that cannot be represented.
+func ParseDDB(text string, defaultSection parser.AbilityType) (*parser.ParseResult, []string) {
+	result := &parser.ParseResult{
+		Traits:       []parser.Ability{},
+		Actions:      []parser.Ability{},
+		BonusActions: []parser.Ability{},
+		Reactions:    []parser.Ability{},
+	}
+	var warnings []string
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	current := defaultSection
+
+	for paragraph := range strings.SplitSeq(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		// A heading may share a paragraph with the first ability when
+		// there's no blank line after it
+		if first, rest, _ := strings.Cut(paragraph, "\n"); isHeading(first) {
+			current, _ = sectionHeading(first)
+			paragraph = strings.TrimSpace(rest)
+			if paragraph == "" {
+				continue
+			}
+		}
+
+		ability, abilityWarnings := parseDDBParagraph(paragraph)
+		ability.Type = current
+		warnings = append(warnings, abilityWarnings...)
+		appendAbility(result, ability)
+	}
+
+	return result, warnings
+}
+
+// parseDDBParagraph converts one "Name. Description" paragraph back to
+// markdown syntax. Recharge rolls in the name go back to their text first.
+func parseDDBParagraph(paragraph string) (parser.Ability, []string) {
+	name, description := splitName(unconvertRecharge(paragraph))
+	description, warnings := UnconvertTags(description)
+	return parser.Ability{Name: name, Description: description}, warnings
+}
+
+// unconvertRecharge replaces recharge rollables with their display text,
+// such as "Recharge 5–6", and leaves every other tag alone
+func unconvertRecharge(text string) string {
+	return rollableTagRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := rollableTagRegex.FindStringSubmatch(match)
+		var data converter.RollableData
+		if json.Unmarshal([]byte(parts[2]), &data) != nil || !strings.EqualFold(data.RollType, "recharge") {
+			return match
+		}
+		return parts[1]
+	})
+}
+
+// UnconvertTags turns [rollable] tags back into "keyword: notation" and
+// [spell] tags back into {{spell:Name}}. Rollables whose roll type has no
+// keyword are replaced with their display value and reported as warnings.
+func UnconvertTags(text string) (string, []string) {
+	var warnings []string
+
+	text = rollableTagRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := rollableTagRegex.FindStringSubmatch(match)
+		display := parts[1]
+
+		var data converter.RollableData
+		if err := json.Unmarshal([]byte(parts[2]), &data); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Invalid rollable data %q", parts[2]))
+			return display
+		}
+
+		keyword, ok := rollTypeKeywords[strings.ToLower(data.RollType)]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Roll type %q has no keyword; kept %q as plain text", data.RollType, display))
+			if display == "" {
+				return data.DiceNotation
+			}
+			return display
+		}
+
+		return keyword + ": " + data.DiceNotation
+	})
+
+	text = spellTagRegex.ReplaceAllString(text, "{{spell:$1}}")
+
+	return text, warnings
+}
+
+// splitName separates a leading "Name. " from the rest of the paragraph.
+// The name of a rollable's action is trusted outright; otherwise the text
+// before the first ". " must look like a title-cased name.
+func splitName(paragraph string) (string, string) {
+	for _, match := range rollableTagRegex.FindAllStringSubmatch(paragraph, -1) {
+		var data converter.RollableData
+		if json.Unmarshal([]byte(match[2]), &data) != nil || data.RollAction == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(paragraph, data.RollAction+". "); ok {
+			return data.RollAction, rest
+		}
+	}
+
+	candidate, rest, found := strings.Cut(paragraph, ". ")
+	if found && looksLikeName(candidate) {
+		return candidate, rest
+	}
+	return "", paragraph
+}
+
+// looksLikeName reports whether text is short and title-cased like an ability name
+func looksLikeName(text string) bool {
+	if len(text) > 60 || strings.ContainsAny(text, "[]{}:;\n") {
+		return false
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 || len(words) > 8 {
+		return false
+	}
+
+	for i, word := range words {
+		first := word[0]
+		switch {
+		case first >= 'A' && first <= 'Z', first >= '0' && first <= '9', first == '(':
+			continue
+		case i > 0 && nameJoinWords[word]:
+			continue
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isHeading reports whether a line names a section
+func isHeading(line string) bool {
+	_, ok := sectionHeading(line)
+	return ok
+}
+
+// sectionHeading returns the section type named by a heading line
+func sectionHeading(line string) (parser.AbilityType, bool) {
+	match := headingRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return 0, false
+	}
+	return parser.SectionType(match[1])
+}
+
+// appendAbility adds an ability to the section matching its type
+func appendAbility(result *parser.ParseResult, ability parser.Ability) {
+	switch ability.Type {
+	case parser.Trait:
+		result.Traits = append(result.Traits, ability)
+	case parser.Action:
+		result.Actions = append(result.Actions, ability)
+	case parser.BonusAction:
+		result.BonusActions = append(result.BonusActions, ability)
+	case parser.Reaction:
+		result.Reactions = append(result.Reactions, ability)
+	}
+}
@@ -0,0 +1,234 @@
+package importer
+
+import (
+	"character-tool/converter"
+	"character-tool/formatter"
+	"character-tool/parser"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestUnconvertTags(t *testing.T) {
+	input := `Melee Weapon Attack: [rollable]+5;{"diceNotation":"1d20+5","rollType":"to hit","rollAction":"Longsword"}[/rollable], reach 5 ft. ` +
+		`Hit: [rollable]8(1d8+3);{"diceNotation":"1d8+3","rollType":"damage","rollAction":"Longsword"}[/rollable] slashing. ` +
+		`Cast [spell]Fireball[/spell].`
+
+	result, warnings := UnconvertTags(input)
+
+	expected := "Melee Weapon Attack: to hit: 1d20+5, reach 5 ft. Hit: damage: 1d8+3 slashing. Cast {{spell:Fireball}}."
+	if result != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, result)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestUnconvertTags_RollTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			"heal maps to healing",
+			`[rollable]7(2d4+2);{"diceNotation":"2d4+2","rollType":"heal","rollAction":"Potion"}[/rollable]`,
+			"healing: 2d4+2",
+			0,
+		},
+		{
+			"save",
+			`[rollable]+3;{"diceNotation":"1d20+3","rollType":"save","rollAction":"Resist"}[/rollable]`,
+			"save: 1d20+3",
+			0,
+		},
+		{
+			"unknown roll type keeps display",
+			`[rollable]+2;{"diceNotation":"1d20+2","rollType":"check","rollAction":"Stealth"}[/rollable]`,
+			"+2",
+			1,
+		},
+		{
+			"invalid json",
+			`[rollable]+2;{not json}[/rollable]`,
+			"+2",
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, warnings := UnconvertTags(tt.input)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("Expected %d warnings, got %v", tt.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestParseDDB_NamesAndPlainText(t *testing.T) {
+	input := "This half-elf has unique abilities from their heritage.\n\n" +
+		"Darkvision. You can see in dim light within 60 feet.\n\n" +
+		"The following traits come from their scholar background. They are rarely used.\n\n" +
+		"Breath Weapon (Recharge 5-6). The dragon exhales fire."
+
+	result, warnings := ParseDDB(input, parser.Trait)
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	expected := []parser.Ability{
+		{Name: "", Description: "This half-elf has unique abilities from their heritage.", Type: parser.Trait},
+		{Name: "Darkvision", Description: "You can see in dim light within 60 feet.", Type: parser.Trait},
+		{Name: "", Description: "The following traits come from their scholar background. They are rarely used.", Type: parser.Trait},
+		{Name: "Breath Weapon (Recharge 5-6)", Description: "The dragon exhales fire.", Type: parser.Trait},
+	}
+
+	if len(result.Traits) != len(expected) {
+		t.Fatalf("Expected %d traits, got %d: %+v", len(expected), len(result.Traits), result.Traits)
+	}
+	for i := range expected {
+		if result.Traits[i] != expected[i] {
+			t.Errorf("Trait %d: expected %+v, got %+v", i, expected[i], result.Traits[i])
+		}
+	}
+}
+
+func TestParseDDB_RechargeRoll(t *testing.T) {
+	input := `Breath Weapon ([rollable]Recharge 5–6;{"diceNotation":"1d6","rollType":"recharge","rollAction":"Breath Weapon"}[/rollable]). The dragon exhales fire.`
+
+	result, warnings := ParseDDB(input, parser.Action)
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+	if len(result.Actions) != 1 {
+		t.Fatalf("Expected 1 action, got %d: %+v", len(result.Actions), result.Actions)
+	}
+	if result.Actions[0].Name != "Breath Weapon (Recharge 5–6)" || result.Actions[0].Description != "The dragon exhales fire." {
+		t.Errorf("Expected the recharge roll back as text in the name, got %+v", result.Actions[0])
+	}
+}
+
+func TestParseDDB_NameFromRollAction(t *testing.T) {
+	// A lower-case name is only recognised because the rollable names it
+	input := `spear of light. Ranged: [rollable]+4;{"diceNotation":"1d20+4","rollType":"to hit","rollAction":"spear of light"}[/rollable].`
+
+	result, _ := ParseDDB(input, parser.Action)
+
+	if len(result.Actions) != 1 {
+		t.Fatalf("Expected 1 action, got %d", len(result.Actions))
+	}
+	if result.Actions[0].Name != "spear of light" {
+		t.Errorf("Expected name from rollAction, got %q", result.Actions[0].Name)
+	}
+	if result.Actions[0].Description != "Ranged: to hit: 1d20+4." {
+		t.Errorf("Unexpected description %q", result.Actions[0].Description)
+	}
+}
+
+func TestParseDDB_Headings(t *testing.T) {
+	input := "Pack Tactics. Advantage with allies.\n\n" +
+		"## Actions\n\n" +
+		"Bite. Nip.\n\n" +
+		"BONUS ACTIONS\n" +
+		"Dash. Move.\n\n" +
+		"Reactions:\n\n" +
+		"Parry. Block."
+
+	result, _ := ParseDDB(input, parser.Trait)
+
+	counts := map[parser.AbilityType]int{
+		parser.Trait:       len(result.Traits),
+		parser.Action:      len(result.Actions),
+		parser.BonusAction: len(result.BonusActions),
+		parser.Reaction:    len(result.Reactions),
+	}
+	for abilityType, count := range counts {
+		if count != 1 {
+			t.Errorf("Expected 1 ability in %s, got %d", abilityType.SectionName(), count)
+		}
+	}
+	if result.BonusActions[0].Name != "Dash" {
+		t.Errorf("Expected Dash after inline heading, got %+v", result.BonusActions[0])
+	}
+}
+
+// format converts markdown to combined D&D Beyond text the same way the CLI does
+func format(t *testing.T, markdown string, spells map[string]bool) string {
+	t.Helper()
+	parsed, err := parser.ParseMarkdown(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections, err := formatter.FormatSections(parsed, spells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return formatter.CombineSections(sections)
+}
+
+func TestRoundTrip_ExampleCharacter(t *testing.T) {
+	content, err := os.ReadFile("../testdata/example-character.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := format(t, string(content), spells)
+
+	imported, warnings := ParseDDB(original, parser.Trait)
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+	markdown := FormatMarkdown(imported)
+
+	if strings.TrimSpace(markdown) != strings.TrimSpace(string(content)) {
+		t.Errorf("Expected imported markdown to match the original.\nExpected:\n%s\nGot:\n%s", content, markdown)
+	}
+	if roundTripped := format(t, markdown, spells); roundTripped != original {
+		t.Errorf("Expected identical output after round trip.\nExpected:\n%s\nGot:\n%s", original, roundTripped)
+	}
+}
+
+func TestRoundTrip_NormalizesNotation(t *testing.T) {
+	markdown := "## Actions\n\n**Claw.** Attack: to hit:d20+2. Hit: damage: 2d4 slashing, healing: 1d8-1, save: d20.\n\nA plain line with [brackets]."
+	spells := map[string]bool{}
+
+	original := format(t, markdown, spells)
+	imported, _ := ParseDDB(original, parser.Trait)
+	if roundTripped := format(t, FormatMarkdown(imported), spells); roundTripped != original {
+		t.Errorf("Expected identical output after round trip.\nExpected:\n%s\nGot:\n%s", original, roundTripped)
+	}
+}
+
+func TestFormatMarkdown_SeparatesPlainText(t *testing.T) {
+	result := &parser.ParseResult{
+		Traits: []parser.Ability{
+			{Description: "An opening note.", Type: parser.Trait},
+			{Name: "Darkvision", Description: "You see in the dark.", Type: parser.Trait},
+			{Description: "A closing note.", Type: parser.Trait},
+		},
+	}
+
+	markdown := FormatMarkdown(result)
+	expected := "## Traits\n\nAn opening note.\n\n**Darkvision.** You see in the dark.\n\n---\n\nA closing note.\n"
+	if markdown != expected {
+		t.Errorf("Expected %q, got %q", expected, markdown)
+	}
+
+	reparsed, err := parser.ParseMarkdown(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reparsed.Traits) != 3 {
+		t.Errorf("Expected the plain paragraphs to stay separate, got %+v", reparsed.Traits)
+	}
+}
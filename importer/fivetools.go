@@ -0,0 +1,272 @@
+package importer
+
+import (
+	"character-tool/parser"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// fiveToolsTagRegex matches innermost 5etools tags such as {@hit 5} or {@spell fireball|phb}
+var fiveToolsTagRegex = regexp.MustCompile(`\{@(\w+)(?: ([^{}]*))?\}`)
+
+// fiveToolsAttacks maps {@atk} codes to stat-block wording
+var fiveToolsAttacks = map[string]string{
+	"mw":    "Melee Weapon Attack:",
+	"rw":    "Ranged Weapon Attack:",
+	"mw,rw": "Melee or Ranged Weapon Attack:",
+	"ms":    "Melee Spell Attack:",
+	"rs":    "Ranged Spell Attack:",
+	"ms,rs": "Melee or Ranged Spell Attack:",
+}
+
+// fiveToolsDisplayAs maps a spellcasting block's displayAs to a section
+var fiveToolsDisplayAs = map[string]parser.AbilityType{
+	"trait":     parser.Trait,
+	"action":    parser.Action,
+	"bonus":     parser.BonusAction,
+	"reaction":  parser.Reaction,
+	"legendary": parser.Action,
+}
+
+// fiveToolsSpellLevels names the keys of a spellcasting block's "spells" map
+var fiveToolsSpellLevels = []string{"Cantrips", "1st level", "2nd level", "3rd level", "4th level", "5th level", "6th level", "7th level", "8th level", "9th level"}
+
+// fiveToolsFile is a 5etools bestiary or homebrew file
+type fiveToolsFile struct {
+	Monster []fiveToolsMonster `json:"monster"`
+}
+
+type fiveToolsMonster struct {
+	Name            string                `json:"name"`
+	Trait           []fiveToolsEntry      `json:"trait"`
+	Action          []fiveToolsEntry      `json:"action"`
+	Bonus           []fiveToolsEntry      `json:"bonus"`
+	Reaction        []fiveToolsEntry      `json:"reaction"`
+	Legendary       []fiveToolsEntry      `json:"legendary"`
+	LegendaryHeader []json.RawMessage     `json:"legendaryHeader"`
+	Spellcasting    []fiveToolsSpellblock `json:"spellcasting"`
+}
+
+type fiveToolsEntry struct {
+	Name    string            `json:"name"`
+	Entries []json.RawMessage `json:"entries"`
+}
+
+type fiveToolsSpellblock struct {
+	Name          string                         `json:"name"`
+	HeaderEntries []json.RawMessage              `json:"headerEntries"`
+	FooterEntries []json.RawMessage              `json:"footerEntries"`
+	Will          []string                       `json:"will"`
+	Daily         map[string][]string            `json:"daily"`
+	Spells        map[string]fiveToolsSpellLevel `json:"spells"`
+	DisplayAs     string                         `json:"displayAs"`
+}
+
+type fiveToolsSpellLevel struct {
+	Slots  int      `json:"slots"`
+	Spells []string `json:"spells"`
+}
+
+// ParseFiveTools reads the monsters in a 5etools bestiary or homebrew JSON
+// file. 5etools tags are turned back into stat-block text and then into roll
+// keywords and {{spell:}} markup.
+func ParseFiveTools(data []byte) ([]Monster, error) {
+	var file fiveToolsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid 5etools JSON: %w", err)
+	}
+	if len(file.Monster) == 0 {
+		return nil, fmt.Errorf("no monsters found (expected a \"monster\" array)")
+	}
+
+	monsters := make([]Monster, 0, len(file.Monster))
+	for _, source := range file.Monster {
+		monster := newMonster(source.Name)
+
+		for _, block := range source.Spellcasting {
+			abilityType, ok := fiveToolsDisplayAs[block.DisplayAs]
+			if !ok {
+				abilityType = parser.Trait
+			}
+			monster.add(abilityType, fiveToolsText(block.Name), fiveToolsSpellcasting(block))
+		}
+
+		sections := []struct {
+			abilityType parser.AbilityType
+			entries     []fiveToolsEntry
+		}{
+			{parser.Trait, source.Trait},
+			{parser.Action, source.Action},
+			{parser.BonusAction, source.Bonus},
+			{parser.Reaction, source.Reaction},
+		}
+		for _, section := range sections {
+			for _, entry := range section.entries {
+				monster.add(section.abilityType, fiveToolsText(entry.Name), fiveToolsEntries(entry.Entries))
+			}
+		}
+
+		var names, texts []string
+		for _, entry := range source.Legendary {
+			names = append(names, fiveToolsText(entry.Name))
+			texts = append(texts, fiveToolsEntries(entry.Entries))
+		}
+		monster.addLegendary(fiveToolsEntries(source.LegendaryHeader), names, texts)
+
+		monsters = append(monsters, monster)
+	}
+
+	return monsters, nil
+}
+
+// fiveToolsEntries flattens 5etools entries (strings, lists and nested
+// named entries) into a single paragraph of plain text
+func fiveToolsEntries(entries []json.RawMessage) string {
+	var parts []string
+
+	for _, raw := range entries {
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			parts = append(parts, fiveToolsText(text))
+			continue
+		}
+
+		var object struct {
+			Name    string            `json:"name"`
+			Entries []json.RawMessage `json:"entries"`
+			Entry   json.RawMessage   `json:"entry"`
+			Items   []json.RawMessage `json:"items"`
+		}
+		if json.Unmarshal(raw, &object) != nil {
+			continue
+		}
+
+		var nested []json.RawMessage
+		nested = append(nested, object.Entries...)
+		if object.Entry != nil {
+			nested = append(nested, object.Entry)
+		}
+		nested = append(nested, object.Items...)
+
+		text = fiveToolsEntries(nested)
+		if object.Name != "" {
+			text = fiveToolsText(object.Name) + ". " + text
+		}
+		parts = append(parts, text)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// fiveToolsSpellcasting writes a spellcasting block as stat-block text
+// with one line per spell list
+func fiveToolsSpellcasting(block fiveToolsSpellblock) string {
+	lines := []string{fiveToolsEntries(block.HeaderEntries)}
+
+	if len(block.Will) > 0 {
+		lines = append(lines, "At will: "+fiveToolsSpellList(block.Will))
+	}
+
+	daily := make([]string, 0, len(block.Daily))
+	for key := range block.Daily {
+		daily = append(daily, key)
+	}
+	slices.Sort(daily)
+	for _, key := range daily {
+		label := strings.TrimSuffix(key, "e") + "/day"
+		if strings.HasSuffix(key, "e") {
+			label += " each"
+		}
+		lines = append(lines, label+": "+fiveToolsSpellList(block.Daily[key]))
+	}
+
+	for level, name := range fiveToolsSpellLevels {
+		spellLevel, ok := block.Spells[fmt.Sprint(level)]
+		if !ok {
+			continue
+		}
+		switch {
+		case level == 0:
+			name += " (at will)"
+		case spellLevel.Slots > 0:
+			name += fmt.Sprintf(" (%d slots)", spellLevel.Slots)
+		}
+		lines = append(lines, name+": "+fiveToolsSpellList(spellLevel.Spells))
+	}
+
+	if footer := fiveToolsEntries(block.FooterEntries); footer != "" {
+		lines = append(lines, footer)
+	}
+
+	return strings.Join(lines, " ")
+}
+
+// fiveToolsSpellList converts and joins a list of spell entries
+func fiveToolsSpellList(spells []string) string {
+	converted := make([]string, len(spells))
+	for i, spell := range spells {
+		converted[i] = fiveToolsText(spell)
+	}
+	return strings.Join(converted, ", ")
+}
+
+// fiveToolsText replaces 5etools tags with stat-block text. Spells become
+// {{spell:}} markup; other tags become their display text.
+func fiveToolsText(text string) string {
+	// Tags can nest, so replace innermost tags until none are left
+	for fiveToolsTagRegex.MatchString(text) {
+		text = fiveToolsTagRegex.ReplaceAllStringFunc(text, func(match string) string {
+			parts := fiveToolsTagRegex.FindStringSubmatch(match)
+			return fiveToolsTag(parts[1], parts[2])
+		})
+	}
+	return text
+}
+
+// fiveToolsTag converts a single tag given its name and content
+func fiveToolsTag(tag, content string) string {
+	// Content is "text|source|display"
+	fields := strings.Split(content, "|")
+	text := fields[0]
+	display := text
+	if len(fields) > 2 && fields[2] != "" {
+		display = fields[2]
+	}
+
+	switch tag {
+	case "atk":
+		if label, ok := fiveToolsAttacks[text]; ok {
+			return label
+		}
+		return "Attack:"
+	case "h":
+		return "Hit: "
+	case "hit":
+		if !strings.HasPrefix(text, "-") {
+			text = "+" + text
+		}
+		return text
+	case "damage", "dice":
+		// The average is already written before the tag
+		return strings.ReplaceAll(text, " ", "")
+	case "dc":
+		return "DC " + text
+	case "d20":
+		if !strings.HasPrefix(text, "-") {
+			text = "+" + text
+		}
+		return text
+	case "recharge":
+		if text == "" || text == "6" {
+			return "(Recharge 6)"
+		}
+		return "(Recharge " + text + "–6)"
+	case "spell":
+		return "{{spell:" + titleCase(text) + "}}"
+	default:
+		return display
+	}
+}
@@ -0,0 +1,39 @@
+package importer
+
+import (
+	"character-tool/parser"
+	"strings"
+)
+
+// FormatMarkdown writes abilities back out in the tool's markdown input
+// format: a "## Section" heading per non-empty section and "**Name.**"
+// before each named ability. Plain text paragraphs after a named ability are
+// preceded by a --- separator so they aren't read back as part of it.
+func FormatMarkdown(result *parser.ParseResult) string {
+	var sections []string
+
+	for _, section := range result.Sections() {
+		if len(section.Abilities) == 0 {
+			continue
+		}
+
+		paragraphs := []string{"## " + section.Type.SectionName()}
+		for i, ability := range section.Abilities {
+			if ability.Name == "" {
+				if i > 0 && section.Abilities[i-1].Name != "" {
+					paragraphs = append(paragraphs, "---")
+				}
+				paragraphs = append(paragraphs, ability.Description)
+				continue
+			}
+			paragraphs = append(paragraphs, "**"+ability.Title()+".** "+ability.Description)
+		}
+
+		sections = append(sections, strings.Join(paragraphs, "\n\n"))
+	}
+
+	if len(sections) == 0 {
+		return ""
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
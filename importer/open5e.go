@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"bytes"
+	"character-tool/parser"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// open5eActionTypes maps the v2 API's action_type to sections
+var open5eActionTypes = map[string]parser.AbilityType{
+	"ACTION":       parser.Action,
+	"BONUS_ACTION": parser.BonusAction,
+	"REACTION":     parser.Reaction,
+}
+
+type open5eMonster struct {
+	Name             string        `json:"name"`
+	SpecialAbilities open5eEntries `json:"special_abilities"`
+	Traits           open5eEntries `json:"traits"`
+	Actions          open5eEntries `json:"actions"`
+	BonusActions     open5eEntries `json:"bonus_actions"`
+	Reactions        open5eEntries `json:"reactions"`
+	LegendaryActions open5eEntries `json:"legendary_actions"`
+	LegendaryDesc    string        `json:"legendary_desc"`
+}
+
+type open5eEntry struct {
+	Name       string `json:"name"`
+	Desc       string `json:"desc"`
+	ActionType string `json:"action_type"`
+}
+
+// open5eEntries accepts the empty string and null the v1 API uses for
+// missing lists
+type open5eEntries []open5eEntry
+
+func (e *open5eEntries) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte(`""`)) || bytes.Equal(data, []byte("null")) {
+		*e = nil
+		return nil
+	}
+	var entries []open5eEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	*e = entries
+	return nil
+}
+
+// ParseOpen5e reads monsters from Open5e API JSON: a single monster, an
+// array of monsters, or a page of results. Both the v1 and v2 API layouts are
+// accepted. Known spells in spellcasting lists become {{spell:}} markup.
+func ParseOpen5e(data []byte, spells map[string]bool) ([]Monster, error) {
+	sources, err := open5eMonsters(data)
+	if err != nil {
+		return nil, err
+	}
+
+	monsters := make([]Monster, 0, len(sources))
+	for _, source := range sources {
+		monster := newMonster(source.Name)
+
+		for _, entry := range append(source.SpecialAbilities, source.Traits...) {
+			monster.add(parser.Trait, entry.Name, open5eDesc(entry, spells))
+		}
+
+		var legendaryNames, legendaryTexts []string
+		for _, entry := range source.Actions {
+			if entry.ActionType == "LEGENDARY_ACTION" {
+				legendaryNames = append(legendaryNames, entry.Name)
+				legendaryTexts = append(legendaryTexts, open5eDesc(entry, spells))
+				continue
+			}
+			abilityType, ok := open5eActionTypes[entry.ActionType]
+			if !ok {
+				abilityType = parser.Action
+			}
+			monster.add(abilityType, entry.Name, open5eDesc(entry, spells))
+		}
+		for _, entry := range source.BonusActions {
+			monster.add(parser.BonusAction, entry.Name, open5eDesc(entry, spells))
+		}
+		for _, entry := range source.Reactions {
+			monster.add(parser.Reaction, entry.Name, open5eDesc(entry, spells))
+		}
+		for _, entry := range source.LegendaryActions {
+			legendaryNames = append(legendaryNames, entry.Name)
+			legendaryTexts = append(legendaryTexts, open5eDesc(entry, spells))
+		}
+		monster.addLegendary(source.LegendaryDesc, legendaryNames, legendaryTexts)
+
+		monsters = append(monsters, monster)
+	}
+
+	return monsters, nil
+}
+
+// open5eMonsters decodes whichever layout the file uses
+func open5eMonsters(data []byte) ([]open5eMonster, error) {
+	data = bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(data, []byte("[")) {
+		var monsters []open5eMonster
+		if err := json.Unmarshal(data, &monsters); err != nil {
+			return nil, fmt.Errorf("invalid Open5e JSON: %w", err)
+		}
+		return monsters, nil
+	}
+
+	var page struct {
+		Results []open5eMonster `json:"results"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("invalid Open5e JSON: %w", err)
+	}
+	if page.Results != nil {
+		return page.Results, nil
+	}
+
+	var monster open5eMonster
+	if err := json.Unmarshal(data, &monster); err != nil {
+		return nil, fmt.Errorf("invalid Open5e JSON: %w", err)
+	}
+	if monster.Name == "" {
+		return nil, fmt.Errorf("no monsters found (expected a monster, an array or a page of results)")
+	}
+	return []open5eMonster{monster}, nil
+}
+
+// open5eDesc returns an entry's description, linking spell lists in
+// spellcasting abilities
+func open5eDesc(entry open5eEntry, spells map[string]bool) string {
+	if strings.Contains(strings.ToLower(entry.Name), "spellcasting") {
+		return linkSpellLists(entry.Desc, spells)
+	}
+	return entry.Desc
+}
@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"os"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestStatBlockToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"attack",
+			"Melee Weapon Attack: +4 to hit, reach 5 ft., one target. Hit: 5 (1d6 + 2) slashing damage.",
+			"Melee Weapon Attack: to hit: 1d20+4, reach 5 ft., one target. Hit: damage: 1d6+2 slashing damage.",
+		},
+		{
+			"zero and negative bonus",
+			"+0 to hit or −1 to hit, 1 (1d4 − 1) damage.",
+			"to hit: 1d20 or to hit: 1d20-1, damage: 1d4-1 damage.",
+		},
+		{
+			"healing",
+			"regains 7 (2d4 + 2) hit points.",
+			"regains healing: 2d4+2 hit points.",
+		},
+		{
+			"unsupported dice left alone",
+			"takes 9 (2d8) cold damage and 3 (1d3) poison damage.",
+			"takes damage: 2d8 cold damage and 3 (1d3) poison damage.",
+		},
+		{
+			"line breaks and bullets folded",
+			"Spells:\n\n* Cantrips: light\n* 1st level: shield",
+			"Spells: Cantrips: light 1st level: shield",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := StatBlockToMarkdown(tt.input); result != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseFiveTools(t *testing.T) {
+	monsters, err := ParseFiveTools(readFixture(t, "5etools-bestiary.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monsters) != 2 {
+		t.Fatalf("Expected 2 monsters, got %d", len(monsters))
+	}
+
+	goblin := monsters[0]
+	if goblin.Slug() != "goblin" {
+		t.Errorf("Expected slug goblin, got %q", goblin.Slug())
+	}
+	expectedTrait := "The goblin can take the Disengage or Hide action as a bonus action on each of its turns."
+	if goblin.Result.Traits[0].Description != expectedTrait {
+		t.Errorf("Expected tags replaced by display text, got %q", goblin.Result.Traits[0].Description)
+	}
+	expectedAction := "Melee Weapon Attack: to hit: 1d20+4, reach 5 ft., one target. Hit: damage: 1d6+2 slashing damage."
+	if goblin.Result.Actions[0].Description != expectedAction {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedAction, goblin.Result.Actions[0].Description)
+	}
+
+	dragon := monsters[1]
+	if dragon.Slug() != "young-red-dragon" {
+		t.Errorf("Expected slug young-red-dragon, got %q", dragon.Slug())
+	}
+
+	expectedSpellcasting := "The dragon's innate spellcasting ability is Charisma (spell save DC 17). " +
+		"At will: {{spell:Fire Bolt}} 1/day each: {{spell:Fireball}}, {{spell:Wall of Fire}}"
+	if dragon.Result.Traits[0].Description != expectedSpellcasting {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedSpellcasting, dragon.Result.Traits[0].Description)
+	}
+
+	expectedActions := []parser.Ability{
+		{Name: "Bite", Description: "Melee Weapon Attack: to hit: 1d20+10, reach 10 ft., one target. Hit: damage: 2d10+6 piercing damage plus damage: 1d6 fire damage.", Type: parser.Action},
+		{Name: "Fire Breath (Recharge 5–6)", Description: "The dragon exhales fire in a 30-foot cone. Each creature in that area must make a DC 17 Dexterity saving throw, taking damage: 16d6 fire damage on a failed save, or half as much damage on a successful one.", Type: parser.Action},
+		{Name: "", Description: "Legendary Actions: The dragon can take 3 legendary actions.", Type: parser.Action},
+		{Name: "Tail Attack", Description: "The dragon makes a tail attack.", Type: parser.Action},
+	}
+	if len(dragon.Result.Actions) != len(expectedActions) {
+		t.Fatalf("Expected %d actions, got %+v", len(expectedActions), dragon.Result.Actions)
+	}
+	for i := range expectedActions {
+		if dragon.Result.Actions[i] != expectedActions[i] {
+			t.Errorf("Action %d:\nExpected %+v\nGot      %+v", i, expectedActions[i], dragon.Result.Actions[i])
+		}
+	}
+	if len(dragon.Warnings) != 1 {
+		t.Errorf("Expected a warning about legendary actions, got %v", dragon.Warnings)
+	}
+
+	if dragon.Result.Reactions[0].Description != "Swats a creature. Knocks it prone." {
+		t.Errorf("Expected flattened list, got %q", dragon.Result.Reactions[0].Description)
+	}
+}
+
+func TestParseFiveTools_NoMonsters(t *testing.T) {
+	if _, err := ParseFiveTools([]byte(`{"spell": []}`)); err == nil {
+		t.Error("Expected error for a file without monsters")
+	}
+}
+
+func TestParseOpen5e(t *testing.T) {
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monsters, err := ParseOpen5e(readFixture(t, "open5e-monsters.json"), spells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monsters) != 2 {
+		t.Fatalf("Expected 2 monsters, got %d", len(monsters))
+	}
+
+	mage := monsters[0]
+	expectedSpellcasting := "The mage is a 9th-level spellcaster. Its spellcasting ability is Intelligence (spell save DC 14, to hit: 1d20+6 with spell attacks). " +
+		"The mage has the following wizard spells prepared: " +
+		"Cantrips (at will): {{spell:Fire Bolt}}, {{spell:Light}}, {{spell:Mage Hand}}, {{spell:Prestidigitation}} " +
+		"1st level (4 slots): {{spell:Detect Magic}}, {{spell:Mage Armor}}, {{spell:Magic Missile}}, {{spell:Shield}} " +
+		"3rd level (3 slots): {{spell:Counterspell}}, {{spell:Fireball}}, {{spell:Fly}}"
+	if mage.Result.Traits[0].Description != expectedSpellcasting {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedSpellcasting, mage.Result.Traits[0].Description)
+	}
+	expectedDagger := "Melee or Ranged Weapon Attack: to hit: 1d20+6, reach 5 ft. or range 20/60 ft., one target. Hit: damage: 1d4+2 piercing damage."
+	if mage.Result.Actions[0].Description != expectedDagger {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedDagger, mage.Result.Actions[0].Description)
+	}
+	if len(mage.Result.Reactions) != 0 || len(mage.Warnings) != 0 {
+		t.Errorf("Expected empty v1 fields to be skipped, got %+v and %v", mage.Result.Reactions, mage.Warnings)
+	}
+
+	// v2 layout: one actions list with action_type
+	acolyte := monsters[1]
+	if len(acolyte.Result.Actions) != 3 || len(acolyte.Result.BonusActions) != 1 || len(acolyte.Result.Reactions) != 1 {
+		t.Errorf("Expected actions split by action_type, got %+v", acolyte.Result)
+	}
+	if acolyte.Result.BonusActions[0].Description != "A creature regains healing: 2d4+2 hit points." {
+		t.Errorf("Unexpected healing conversion %q", acolyte.Result.BonusActions[0].Description)
+	}
+}
+
+func TestParseOpen5e_Layouts(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		count int
+	}{
+		{"single monster", `{"name": "Goblin", "actions": []}`, 1},
+		{"array", `[{"name": "Goblin"}, {"name": "Orc"}]`, 2},
+		{"page", `{"results": [{"name": "Goblin"}]}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monsters, err := ParseOpen5e([]byte(tt.input), map[string]bool{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(monsters) != tt.count {
+				t.Errorf("Expected %d monsters, got %d", tt.count, len(monsters))
+			}
+		})
+	}
+
+	if _, err := ParseOpen5e([]byte(`{"count": 0}`), map[string]bool{}); err == nil {
+		t.Error("Expected error for JSON without a monster")
+	}
+}
+
+func TestParseFiveTools_RoundTripsThroughFormatter(t *testing.T) {
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		t.Fatal(err)
+	}
+	monsters, err := ParseFiveTools(readFixture(t, "5etools-bestiary.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The imported markdown must go through the normal pipeline cleanly
+	for _, monster := range monsters {
+		markdown := FormatMarkdown(monster.Result)
+		parsed, err := parser.ParseMarkdown(markdown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := format(t, markdown, spells); got == "" {
+			t.Errorf("Expected formatted output for %s", monster.Name)
+		}
+		if len(parsed.Actions) != len(monster.Result.Actions) {
+			t.Errorf("%s: expected %d actions after re-parsing, got %d", monster.Name, len(monster.Result.Actions), len(parsed.Actions))
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pre-compiled patterns for SRD stat-block wording
+var (
+	toHitRegex     = regexp.MustCompile(`([+\-−]\s?\d+) to hit\b`)
+	spellLineRegex = regexp.MustCompile(`(?m)^(.*:[ \t]*)([^:\n]+)$`)
+	bulletRegex    = regexp.MustCompile(`(?m)^[ \t]*[*•][ \t]+`)
+	slugRegex      = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Monster is one creature read from an SRD data file
+type Monster struct {
+	Name     string
+	Result   *parser.ParseResult
+	Warnings []string
+}
+
+// Slug returns a file name stem for the monster ("Adult Red Dragon" → "adult-red-dragon")
+func (m Monster) Slug() string {
+	return strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(m.Name), "-"), "-")
+}
+
+// newMonster creates a monster with empty sections
+func newMonster(name string) Monster {
+	return Monster{
+		Name: name,
+		Result: &parser.ParseResult{
+			Traits:       []parser.Ability{},
+			Actions:      []parser.Ability{},
+			BonusActions: []parser.Ability{},
+			Reactions:    []parser.Ability{},
+		},
+	}
+}
+
+// add appends an ability whose description is stat-block text
+func (m *Monster) add(abilityType parser.AbilityType, name, text string) {
+	appendAbility(m.Result, parser.Ability{
+		Name:        strings.TrimSpace(name),
+		Description: StatBlockToMarkdown(text),
+		Type:        abilityType,
+	})
+}
+
+// addLegendary appends legendary actions to Actions after a plain text
+// paragraph introducing them, since character sheets have no legendary section
+func (m *Monster) addLegendary(header string, names, texts []string) {
+	if len(names) == 0 {
+		return
+	}
+	intro := "Legendary Actions."
+	if header = strings.TrimSpace(header); header != "" {
+		intro = "Legendary Actions: " + header
+	}
+	m.add(parser.Action, "", intro)
+	for i := range names {
+		m.add(parser.Action, names[i], texts[i])
+	}
+	m.Warnings = append(m.Warnings, fmt.Sprintf("%s: %d legendary actions added to Actions", m.Name, len(names)))
+}
+
+// StatBlockToMarkdown rewrites stat-block wording as roll keywords:
+// "+5 to hit" becomes "to hit: 1d20+5", "8 (1d8 + 3) slashing damage" becomes
+// "damage: 1d8+3 slashing damage" and "7 (2d4 + 2) hit points" becomes
+// "healing: 2d4+2 hit points". Dice the converter wouldn't accept are left as
+// written. Line breaks and list bullets are folded into spaces.
+func StatBlockToMarkdown(text string) string {
+	text = bulletRegex.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(text), " ")
+
+	text = toHitRegex.ReplaceAllStringFunc(text, func(match string) string {
+		return converter.ToHitKeyword(toHitRegex.FindStringSubmatch(match)[1])
+	})
+
+	return converter.ConvertAverageRolls(text)
+}
+
+// linkSpellLists wraps known spells in comma-separated lists after a colon,
+// such as "1st level (4 slots): detect magic, shield", in {{spell:}} markup
+func linkSpellLists(text string, spells map[string]bool) string {
+	return spellLineRegex.ReplaceAllStringFunc(text, func(line string) string {
+		parts := spellLineRegex.FindStringSubmatch(line)
+		items := strings.Split(parts[2], ",")
+
+		for i, item := range items {
+			name := strings.TrimSpace(item)
+			// Drop notes such as "shield (self only)" and emphasis markers
+			if open := strings.Index(name, " ("); open > 0 {
+				name = name[:open]
+			}
+			name = strings.Trim(name, "*_ ")
+
+			if converter.IsValidSpell(name, spells) {
+				items[i] = strings.Replace(item, name, "{{spell:"+titleCase(name)+"}}", 1)
+			}
+		}
+
+		return parts[1] + strings.Join(items, ",")
+	})
+}
+
+// titleCase capitalizes each word of a spell name except joining words
+func titleCase(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		if i > 0 && nameJoinWords[word] {
+			continue
+		}
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"character-tool/parser"
+	"character-tool/scaffold"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	initClass     string
+	initMonster   string
+	initLevel     int
+	initCR        int
+	initName      string
+	initTemplates string
+	initForce     bool
+	initStdout    bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a starter character markdown file for a class or monster archetype",
+	Long: `Writes a starter markdown file with frontmatter, the four standard sections
+and placeholder abilities that already use the {{spell:Name}} and "to hit:" /
+"damage:" keyword syntax, ready to edit and format.
+
+Use --class with --level for a player character, or --monster with --cr for a
+monster archetype. Attack bonuses, save DCs and damage dice are filled in for
+the level or challenge rating.
+
+The file is named after --name (default: the class or archetype) and written
+to the output directory; use --force to overwrite an existing file or --stdout
+to print it instead.
+
+Templates are embedded in the tool. To change one, or add your own, put a file
+at <template-dir>/class/<name>.md or <template-dir>/monster/<name>.md; it is
+used instead of the built-in template of the same name.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := scaffold.Options{Name: initName, Dir: initTemplates}
+		switch {
+		case initClass != "" && initMonster != "":
+			return fmt.Errorf("--class and --monster can't be used together")
+		case initClass != "":
+			opts.Kind, opts.Template, opts.Level = scaffold.Class, initClass, initLevel
+		case initMonster != "":
+			opts.Kind, opts.Template, opts.Level = scaffold.Monster, initMonster, initCR
+		default:
+			return fmt.Errorf("use --class (%s) or --monster (%s)",
+				strings.Join(scaffold.Templates(scaffold.Class, initTemplates), ", "),
+				strings.Join(scaffold.Templates(scaffold.Monster, initTemplates), ", "))
+		}
+
+		cmd.SilenceUsage = true
+		return runInit(opts, outputDir)
+	},
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initClass, "class", "", "character class, e.g. wizard")
+	initCmd.Flags().StringVar(&initMonster, "monster", "", "monster archetype, e.g. brute or caster")
+	initCmd.Flags().IntVar(&initLevel, "level", 1, "character level (1-20)")
+	initCmd.Flags().IntVar(&initCR, "cr", 1, "monster challenge rating (0-30)")
+	initCmd.Flags().StringVar(&initName, "name", "", "character name (default: the class or archetype)")
+	initCmd.Flags().StringVar(&initTemplates, "template-dir", defaultTemplatesDir(), "directory of templates that override or add to the built-in ones")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite an existing markdown file")
+	initCmd.Flags().BoolVar(&initStdout, "stdout", false, "print the markdown to stdout instead of writing a file")
+	rootCmd.AddCommand(initCmd)
+}
+
+// defaultTemplatesDir is the character-tool/templates directory in the
+// user's config directory, or "" if there is none
+func defaultTemplatesDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "character-tool", "templates")
+}
+
+func runInit(opts scaffold.Options, outputDir string) error {
+	markdown, err := scaffold.Generate(opts)
+	if err != nil {
+		return err
+	}
+
+	if initStdout {
+		fmt.Print(markdown)
+		return nil
+	}
+
+	outputPath := filepath.Join(outputDir, scaffold.FileName(opts))
+	if !initForce {
+		if _, err := os.Stat(outputPath); err == nil {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", outputPath)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, []byte(markdown), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+
+	parsed, err := parser.ParseMarkdown(markdown)
+	if err != nil {
+		return err
+	}
+	abilities := 0
+	for _, section := range parsed.Sections() {
+		abilities += len(section.Abilities)
+	}
+	fmt.Printf("✓ Wrote %s (%d abilities)\n", outputPath, abilities)
+
+	return nil
+}
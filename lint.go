@@ -0,0 +1,79 @@
+package main
+
+import (
+	"character-tool/batch"
+	"character-tool/lint"
+	"character-tool/parser"
+	"character-tool/render"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var lintStyle string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [files...]",
+	Short: "Check character markdown files against the house style",
+	Long: `Parses each file and reports anything that doesn't follow the house style,
+one "file:line: message" per issue. The command exits non-zero if any issue is
+found, so it can run in a pre-commit hook or CI.
+
+Ability names can be written three ways, and all of them format the same:
+  - bold:    **Longsword.** Description (the default house style)
+  - colon:   **Longsword:** Description
+  - heading: ### Longsword, with the description on the lines below
+
+The name-style rule flags every ability whose name isn't written in the style
+chosen with --style. The attack rule flags "Melee Weapon Attack:" stat lines
+with parts that can't be read, such as a missing reach or range.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputs, err := collectInputs(args)
+		if err != nil {
+			return err
+		}
+		style, ok := parser.ParseNameStyle(lintStyle)
+		if !ok {
+			return fmt.Errorf("unknown name style %q (must be bold, colon or heading)", lintStyle)
+		}
+		files, err := batch.ExpandInputs(inputs, render.MarkdownSuffixes()...)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no markdown files found in %v", inputs)
+		}
+
+		cmd.SilenceUsage = true
+		return runLint(os.Stdout, files, lint.NameStyle(style), lint.Attacks)
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintStyle, "style", "bold", "house style for ability names: bold, colon or heading")
+	rootCmd.AddCommand(lintCmd)
+}
+
+// runLint checks each file and prints its issues, returning an error if
+// any file has issues or can't be read
+func runLint(w io.Writer, files []string, rules ...lint.Rule) error {
+	total := 0
+	for _, file := range files {
+		parsed, err := parseInput(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		issues := lint.Check(parsed, rules...)
+		for _, issue := range issues {
+			fmt.Fprintf(w, "%s:%d: %s (%s)\n", file, issue.Line, issue.Message, issue.Rule)
+		}
+		total += len(issues)
+	}
+
+	if total > 0 {
+		return fmt.Errorf("found %d issues", total)
+	}
+	return nil
+}
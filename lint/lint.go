@@ -0,0 +1,72 @@
+package lint
+
+import (
+	"character-tool/parser"
+	"fmt"
+	"sort"
+)
+
+// Issue is one problem found in a character file
+type Issue struct {
+	Line    int
+	Rule    string
+	Message string
+}
+
+// String formats the issue as "line N: message (rule)"
+func (i Issue) String() string {
+	return fmt.Sprintf("line %d: %s (%s)", i.Line, i.Message, i.Rule)
+}
+
+// Rule checks a parsed character and reports any issues
+type Rule func(result *parser.ParseResult) []Issue
+
+// Check runs every rule over a parsed character and returns the issues in
+// line order
+func Check(result *parser.ParseResult, rules ...Rule) []Issue {
+	var issues []Issue
+	for _, rule := range rules {
+		issues = append(issues, rule(result)...)
+	}
+	sort.SliceStable(issues, func(a, b int) bool {
+		return issues[a].Line < issues[b].Line
+	})
+	return issues
+}
+
+// NameStyle reports named abilities that aren't written in the house style
+func NameStyle(style parser.NameStyle) Rule {
+	return func(result *parser.ParseResult) []Issue {
+		var issues []Issue
+		for _, section := range result.Sections() {
+			for _, ability := range section.Abilities {
+				if ability.Name == "" || ability.Style == style {
+					continue
+				}
+				issues = append(issues, Issue{
+					Line:    ability.Line,
+					Rule:    "name-style",
+					Message: fmt.Sprintf("%s is written as %s, use %s", ability.Name, ability.Style.Format(ability.Name), style.Format(ability.Name)),
+				})
+			}
+		}
+		return issues
+	}
+}
+
+// Attacks reports attack stat lines the parser couldn't fully read, such as
+// an attack with no reach or range. Notes about inferred rolls aren't issues.
+func Attacks(result *parser.ParseResult) []Issue {
+	var issues []Issue
+	for _, warning := range result.Warnings {
+		if warning.Confidence != 0 {
+			continue
+		}
+		issues = append(issues, Issue{
+			Line:    warning.Line,
+			Rule:    "attack",
+			Message: warning.Ability + ": " + warning.Message,
+		})
+	}
+	return issues
+}
@@ -0,0 +1,88 @@
+package lint
+
+import (
+	"character-tool/parser"
+	"testing"
+)
+
+func TestNameStyle(t *testing.T) {
+	input := `## Traits
+
+**Darkvision.** You can see in the dark.
+
+## Actions
+
+### Longsword
+
+to hit: 1d20+5, damage: 1d8+3 slashing damage.
+
+**Bite:** to hit: 1d20+4, damage: 1d6+2 piercing damage.
+
+---
+
+A plain paragraph is never flagged.`
+
+	result, err := parser.ParseMarkdown(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		style    parser.NameStyle
+		expected []string
+	}{
+		{parser.BoldName, []string{
+			"line 7: Longsword is written as ### Longsword, use **Longsword.** (name-style)",
+			"line 11: Bite is written as **Bite:**, use **Bite.** (name-style)",
+		}},
+		{parser.ColonName, []string{
+			"line 3: Darkvision is written as **Darkvision.**, use **Darkvision:** (name-style)",
+			"line 7: Longsword is written as ### Longsword, use **Longsword:** (name-style)",
+		}},
+		{parser.HeadingName, []string{
+			"line 3: Darkvision is written as **Darkvision.**, use ### Darkvision (name-style)",
+			"line 11: Bite is written as **Bite:**, use ### Bite (name-style)",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style.String(), func(t *testing.T) {
+			issues := Check(result, NameStyle(tt.style))
+			if len(issues) != len(tt.expected) {
+				t.Fatalf("Expected %d issues, got %v", len(tt.expected), issues)
+			}
+			for i, issue := range issues {
+				if issue.String() != tt.expected[i] {
+					t.Errorf("Expected %q, got %q", tt.expected[i], issue.String())
+				}
+			}
+		})
+	}
+}
+
+func TestAttacks(t *testing.T) {
+	input := `## Actions
+
+**Longsword.** Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 1d8+3 slashing damage.
+
+**Claw.** Melee Weapon Attack: to hit: 1d20+5, one target. Hit: damage: 1d6+3 slashing damage.`
+
+	result, err := parser.ParseMarkdown(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues := Check(result, Attacks)
+	if len(issues) != 1 || issues[0].String() != "line 5: Claw: attack has no reach or range (attack)" {
+		t.Errorf("Expected one issue for Claw, got %v", issues)
+	}
+
+	// Notes about inferred rolls aren't issues
+	result, err = parser.ParseMarkdownWith("## Actions\n\n**Bite.** Melee Weapon Attack: +4 to hit, reach 5 ft., one target. Hit: 1d6 piercing damage.", parser.Options{InferRolls: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issues := Check(result, Attacks); len(issues) != 0 {
+		t.Errorf("Expected no issues with inferred rolls, got %v", issues)
+	}
+}
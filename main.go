@@ -1,25 +1,41 @@
 package main
 
 import (
+	"character-tool/batch"
 	"character-tool/converter"
 	"character-tool/formatter"
 	"character-tool/parser"
+	"character-tool/render"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	inputFile string
-	outputDir string
-	verbose   bool
-	vaultMode bool
+	inputFiles []string
+	outputDir  string
+	verbose    bool
+	vaultMode  bool
+	workers    int
+	combined   bool
+	toStdout   bool
+	section    string
+	format     string
+	tmplName   string
+	keepMd     bool
+	rulesetArg string
+	inferRolls bool
+	// ruleset is --ruleset, validated before any command runs
+	ruleset parser.Ruleset
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "character-tool",
+	Use:   "character-tool [flags] [files, directories or globs...]",
 	Short: "Convert D&D character markdown to D&D Beyond format",
 	Long: `A tool that converts markdown character descriptions into D&D Beyond-formatted
 blocks with spell links and rollable dice notation.
@@ -27,19 +43,60 @@ blocks with spell links and rollable dice notation.
 The tool parses markdown files with structured headers (Traits, Actions, Bonus Actions,
 Reactions) and converts:
   - {{spell:SpellName}} syntax to clickable spell links
-  - Dice notation (1d20+5) with keywords (to hit:, damage:) to rollable format
-  - Validates spell names and dice notation`,
+  - Dice notation (1d20+5) with keywords (to hit:, damage:) to rollable format,
+    or 2024 stat-block wording ("Melee Attack Roll: +5"), detected or set with --ruleset,
+    or plain wording ("+5 to hit", "10 (3d6) fire damage") with --infer-rolls
+  - Validates spell names and dice notation
+
+Several inputs can be given at once as files, directories (searched recursively
+for .md files) or glob patterns. Each file is then written to its own
+subdirectory of the output directory and processed in parallel.`,
+	// Positional arguments are inputs, not subcommand names
+	Args: cobra.ArbitraryArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var ok bool
+		ruleset, ok = parser.ParseRuleset(rulesetArg)
+		if !ok {
+			return fmt.Errorf("unknown ruleset %q (must be auto, 2014 or 2024)", rulesetArg)
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return run(inputFile, outputDir, verbose, vaultMode)
+		inputs, err := collectInputs(args)
+		if err != nil {
+			return err
+		}
+		opts, err := outputFlags()
+		if err != nil {
+			return err
+		}
+		opts.stdout = toStdout
+
+		// Failures past this point are per-file problems, not usage errors
+		cmd.SilenceUsage = true
+		return run(inputs, outputDir, verbose, vaultMode, workers, opts)
 	},
 }
 
+// keepMarkdownUsage is the help for --keep-markdown, which every command that
+// writes D&D Beyond text registers
+const keepMarkdownUsage = "keep inline markdown (*italic*, **bold**, [links](...)) in D&D Beyond text instead of stripping it"
+
 func init() {
-	rootCmd.Flags().StringVarP(&inputFile, "input", "i", "", "path to input markdown file (required)")
-	rootCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "output directory for generated files")
-	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "show detailed validation warnings")
-	rootCmd.Flags().BoolVar(&vaultMode, "vault-mode", false, "output files to same directory as input file (Obsidian integration)")
-	rootCmd.MarkFlagRequired("input")
+	rootCmd.PersistentFlags().StringArrayVarP(&inputFiles, "input", "i", nil, "input markdown file, directory or glob (repeatable)")
+	rootCmd.PersistentFlags().StringVarP(&outputDir, "output", "o", ".", "output directory for generated files")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "show detailed validation warnings")
+	rootCmd.PersistentFlags().BoolVar(&vaultMode, "vault-mode", false, "output files to same directory as input file (Obsidian integration)")
+	rootCmd.PersistentFlags().IntVarP(&workers, "workers", "j", runtime.NumCPU(), "number of files to process in parallel")
+	rootCmd.PersistentFlags().BoolVar(&combined, "combined", false, "write all sections to a single file with section headings")
+	rootCmd.PersistentFlags().StringVar(&section, "section", "", "only output one section (traits, actions, bonus-actions, reactions)")
+	rootCmd.PersistentFlags().StringVar(&format, "format", render.Default, "output format: "+strings.Join(render.Names(), ", "))
+	rootCmd.PersistentFlags().StringVar(&tmplName, "template", "", "render through a text/template file or built-in template ("+strings.Join(render.TemplateNames(), ", ")+")")
+	rootCmd.PersistentFlags().StringVar(&rulesetArg, "ruleset", "auto", "stat-block wording to read: auto, 2014 or 2024 (\"Melee Attack Roll: +5\")")
+	rootCmd.PersistentFlags().BoolVar(&inferRolls, "infer-rolls", false, "also convert rolls written without keywords (\"+5 to hit\", \"10 (3d6) fire damage\")")
+	rootCmd.Flags().BoolVar(&toStdout, "stdout", false, "print formatted output to stdout instead of writing files")
+	// Only the commands that write D&D Beyond text strip markdown
+	rootCmd.Flags().BoolVar(&keepMd, "keep-markdown", false, keepMarkdownUsage)
 }
 
 func main() {
@@ -48,96 +105,346 @@ func main() {
 	}
 }
 
-func run(inputFile, outputDir string, verbose, vaultMode bool) error {
-	// Read input file
-	content, err := os.ReadFile(inputFile)
+// collectInputs combines --input flags with positional arguments
+func collectInputs(args []string) ([]string, error) {
+	inputs := append(append([]string{}, inputFiles...), args...)
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no input given: use --input or pass files as arguments")
+	}
+	return inputs, nil
+}
+
+// outputFlags builds output options from the shared output flags
+func outputFlags() (outputOptions, error) {
+	sectionType, err := parseSectionFlag(section)
 	if err != nil {
-		return fmt.Errorf("failed to read input file: %w", err)
+		return outputOptions{}, err
 	}
+	opts := outputOptions{combined: combined, section: sectionType, keepMarkdown: keepMd}
 
-	// In vault mode, output to the same directory as input file
-	if vaultMode {
-		outputDir = filepath.Dir(inputFile)
+	if tmplName != "" {
+		if format != render.Default {
+			return outputOptions{}, fmt.Errorf("--template and --format can't be used together")
+		}
+		opts.renderer, err = render.LoadTemplate(tmplName)
+		return opts, err
+	}
+
+	renderer, ok := render.Get(format)
+	if !ok {
+		return outputOptions{}, fmt.Errorf("unknown format %q (must be one of %s)", format, strings.Join(render.Names(), ", "))
+	}
+	opts.renderer = renderer
+	return opts, nil
+}
+
+// resolveInputs expands inputs into individual files and assigns each an
+// output directory. Files the tool wrote itself, named like the renderer's
+// or another renderer's markdown output or found in another input's output
+// directory, are skipped.
+func resolveInputs(inputs []string, outputDir string, vaultMode bool, renderer render.Renderer) ([]string, map[string]string, error) {
+	files, err := batch.ExpandInputs(inputs, render.MarkdownSuffixes(renderer)...)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Parse markdown
-	result, err := parser.ParseMarkdown(string(content))
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no markdown files found in %v", inputs)
+	}
+
+	// The layout is decided before filtering, so files keep their
+	// subdirectories even if only one is left
+	outputDirs, err := batch.OutputDirs(files, outputDir, vaultMode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("conflicting output directories: %w", err)
+	}
+	files = batch.WithoutOutputs(files, outputDirs)
+
+	return files, outputDirs, nil
+}
+
+func run(inputs []string, outputDir string, verbose, vaultMode bool, workers int, opts outputOptions) error {
+	// Resolve globs and directories into individual files
+	files, outputDirs, err := resolveInputs(inputs, outputDir, vaultMode, opts.renderer)
 	if err != nil {
-		return fmt.Errorf("failed to parse markdown: %w", err)
+		return err
 	}
 
-	// Load spells
+	// Load spells once and share them between workers
 	spells, err := converter.LoadSpells()
 	if err != nil {
 		return fmt.Errorf("failed to load spell list: %w", err)
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	results := batch.Run(files, workers, func(input string) batch.Result {
+		return processFile(input, outputDirs[input], spells, opts)
+	})
+
+	// With --stdout the formatted text owns stdout, so status goes to stderr
+	status := io.Writer(os.Stdout)
+	if opts.stdout {
+		status = os.Stderr
+		printOutputs(os.Stdout, results)
+	}
+
+	if len(results) == 1 {
+		if results[0].Err != nil {
+			return results[0].Err
+		}
+		if opts.stdout {
+			printWarnings(status, results[0].Warnings, verbose)
+		} else {
+			printResult(status, results[0], verbose)
+		}
+		return nil
 	}
 
-	// Track all warnings and created files
-	var allWarnings []string
-	var createdFiles []string
-	totalAbilities := 0
+	return printBatchSummary(status, results, verbose)
+}
+
+// outputOptions controls how formatted sections are written
+type outputOptions struct {
+	renderer render.Renderer
+	combined bool
+	stdout   bool
+	// section limits output to a single section when non-nil
+	section *parser.AbilityType
+	// keepMarkdown leaves inline markdown in the D&D Beyond text
+	keepMarkdown bool
+}
 
-	// Process and write each section
-	sections := map[string]struct {
-		abilities []parser.Ability
-		filename  string
-	}{
-		"Traits":        {result.Traits, "traits.txt"},
-		"Actions":       {result.Actions, "actions.txt"},
-		"Bonus Actions": {result.BonusActions, "bonus-actions.txt"},
-		"Reactions":     {result.Reactions, "reactions.txt"},
+// parseSectionFlag validates the --section flag value
+func parseSectionFlag(name string) (*parser.AbilityType, error) {
+	if name == "" {
+		return nil, nil
+	}
+	abilityType, ok := parser.SectionType(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown section %q (must be traits, actions, bonus-actions or reactions)", name)
 	}
+	return &abilityType, nil
+}
 
-	for sectionName, section := range sections {
-		if len(section.abilities) == 0 {
-			continue
+// processFile parses a single input and renders it in the requested format,
+// then writes the output files or, in stdout mode, stores the rendered text
+// on the result
+func processFile(inputFile, outputDir string, spells map[string]bool, opts outputOptions) batch.Result {
+	result := batch.Result{Input: inputFile, OutputDir: outputDir}
+
+	parsed, err := parseInput(inputFile)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if opts.section != nil {
+		parsed = onlySection(parsed, *opts.section)
+	}
+
+	output, err := opts.renderer.Render(render.Document{
+		Source: inputFile,
+		Stem:   batch.Stem(inputFile),
+		Title:  formatter.DisplayTitle(inputFile),
+		Result: parsed,
+		Spells: spells,
+	}, render.Options{Combined: opts.combined, Section: opts.section, KeepMarkdown: opts.keepMarkdown})
+	for _, warning := range parsed.Warnings {
+		result.Warnings = append(result.Warnings, warning.String())
+	}
+	result.Warnings = append(result.Warnings, output.Warnings...)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Abilities = output.Abilities
+
+	if opts.stdout {
+		result.Output = output.Stdout
+		return result
+	}
+	return writeFiles(result, output.Files)
+}
+
+// writeFiles writes rendered files to the result's output directory
+func writeFiles(result batch.Result, files []render.File) batch.Result {
+	if err := os.MkdirAll(result.OutputDir, 0755); err != nil {
+		result.Err = fmt.Errorf("failed to create output directory: %w", err)
+		return result
+	}
+
+	for _, file := range files {
+		outputPath := filepath.Join(result.OutputDir, file.Name)
+		if err := os.WriteFile(outputPath, []byte(file.Content), 0644); err != nil {
+			result.Err = fmt.Errorf("failed to write %s: %w", file.Name, err)
+			return result
 		}
+		result.Files = append(result.Files, fmt.Sprintf("%s (%d abilities)", outputPath, file.Abilities))
+	}
 
-		formatted, warnings, err := formatter.FormatAbilities(section.abilities, spells)
-		if err != nil {
-			return fmt.Errorf("failed to format %s: %w", sectionName, err)
+	return result
+}
+
+// parseInput reads and parses a single input file
+func parseInput(inputFile string) (*parser.ParseResult, error) {
+	// Read input file
+	content, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	// Parse markdown, reading 2024 wording and rolls without keywords as
+	// --ruleset and --infer-rolls say
+	parsed, err := parser.ParseMarkdownWith(string(content), parser.Options{Ruleset: ruleset, InferRolls: inferRolls})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse markdown: %w", err)
+	}
+
+	return parsed, nil
+}
+
+// onlySection returns a copy of the parse result with every other section emptied
+func onlySection(parsed *parser.ParseResult, abilityType parser.AbilityType) *parser.ParseResult {
+	filtered := &parser.ParseResult{
+		Traits:       []parser.Ability{},
+		Actions:      []parser.Ability{},
+		BonusActions: []parser.Ability{},
+		Reactions:    []parser.Ability{},
+	}
+	switch abilityType {
+	case parser.Trait:
+		filtered.Traits = parsed.Traits
+	case parser.Action:
+		filtered.Actions = parsed.Actions
+	case parser.BonusAction:
+		filtered.BonusActions = parsed.BonusActions
+	case parser.Reaction:
+		filtered.Reactions = parsed.Reactions
+	}
+	filtered.Ruleset = parsed.Ruleset
+	for _, warning := range parsed.Warnings {
+		if warning.Type == abilityType {
+			filtered.Warnings = append(filtered.Warnings, warning)
 		}
+	}
+	return filtered
+}
 
-		// Collect warnings
-		for _, warning := range warnings {
-			allWarnings = append(allWarnings, fmt.Sprintf("[%s] %s", sectionName, warning))
+// formatInput reads, parses and formats a single input file. When only is
+// non-nil, every other section is dropped. The parse warnings, such as
+// attacks that can't be read, are returned as strings.
+func formatInput(inputFile string, spells map[string]bool, only *parser.AbilityType, keepMarkdown bool) ([]formatter.FormattedSection, []string, error) {
+	parsed, err := parseInput(inputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !keepMarkdown {
+		parsed = formatter.StripMarkdown(parsed)
+	}
+	if only != nil {
+		parsed = onlySection(parsed, *only)
+	}
+
+	var warnings []string
+	for _, warning := range parsed.Warnings {
+		warnings = append(warnings, warning.String())
+	}
+
+	// Format each non-empty section in output order
+	sections, err := formatter.FormatSections(parsed, spells)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	return sections, warnings, nil
+}
+
+// sectionWarnings collects section warnings, prefixed with the section name
+func sectionWarnings(sections []formatter.FormattedSection) []string {
+	var warnings []string
+	for _, section := range sections {
+		for _, warning := range section.Warnings {
+			warnings = append(warnings, fmt.Sprintf("[%s] %s", section.Type.SectionName(), warning))
 		}
+	}
+	return warnings
+}
 
-		// Write output file
-		outputPath := filepath.Join(outputDir, section.filename)
-		if err := os.WriteFile(outputPath, []byte(formatted), 0644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", section.filename, err)
+// printOutputs writes the formatted text of each successful result. When
+// several files were processed each is introduced by a "# file" heading.
+func printOutputs(w io.Writer, results []batch.Result) {
+	first := true
+	for _, result := range results {
+		if result.Err != nil || result.Output == "" {
+			continue
 		}
+		if !first {
+			fmt.Fprintln(w)
+		}
+		first = false
+
+		if len(results) > 1 {
+			fmt.Fprintf(w, "# %s\n\n", result.Input)
+		}
+		fmt.Fprintln(w, result.Output)
+	}
+}
+
+// printResult displays the created files and warnings for a single input
+func printResult(w io.Writer, result batch.Result, verbose bool) {
+	fmt.Fprintln(w, "✓ Formatted character abilities")
+	fmt.Fprintln(w, "\nOutput files:")
+	for _, file := range result.Files {
+		fmt.Fprintf(w, "  - %s\n", file)
+	}
 
-		// Track created files
-		createdFiles = append(createdFiles, fmt.Sprintf("%s (%d abilities)", outputPath, len(section.abilities)))
-		totalAbilities += len(section.abilities)
+	if len(result.Warnings) > 0 {
+		fmt.Fprintln(w)
+		printWarnings(w, result.Warnings, verbose)
 	}
+}
 
-	// Display summary
-	fmt.Println("✓ Formatted character abilities")
-	fmt.Println("\nOutput files:")
-	for _, file := range createdFiles {
-		fmt.Printf("  - %s\n", file)
+// printWarnings displays warnings in full when verbose, otherwise just a count
+func printWarnings(w io.Writer, warnings []string, verbose bool) {
+	if len(warnings) == 0 {
+		return
 	}
+	if verbose {
+		fmt.Fprintln(w, "Warnings:")
+		for _, warning := range warnings {
+			fmt.Fprintf(w, "  ! %s\n", warning)
+		}
+	} else {
+		fmt.Fprintf(w, "Warnings: %d found (use --verbose for details)\n", len(warnings))
+	}
+}
 
-	// Display warnings summary
-	if len(allWarnings) > 0 {
-		fmt.Println()
+// printBatchSummary displays one line per input followed by aggregated totals.
+// It returns an error if any input failed so the process exits non-zero.
+func printBatchSummary(w io.Writer, results []batch.Result, verbose bool) error {
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(w, "✗ %s: %v\n", result.Input, result.Err)
+			continue
+		}
+
+		fmt.Fprintf(w, "✓ %s → %s (%d abilities, %d warnings)\n",
+			result.Input, result.OutputDir, result.Abilities, len(result.Warnings))
 		if verbose {
-			fmt.Println("Warnings:")
-			for _, warning := range allWarnings {
-				fmt.Printf("  ! %s\n", warning)
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(w, "    ! %s\n", warning)
 			}
-		} else {
-			fmt.Printf("Warnings: %d found (use --verbose for details)\n", len(allWarnings))
 		}
 	}
 
+	summary := batch.Summarize(results)
+	fmt.Fprintf(w, "\nProcessed %d files: %d succeeded, %d failed\n", summary.Files, summary.Succeeded, summary.Failed)
+	fmt.Fprintf(w, "Abilities: %d, Warnings: %d", summary.Abilities, summary.Warnings)
+	if summary.Warnings > 0 && !verbose {
+		fmt.Fprint(w, " (use --verbose for details)")
+	}
+	fmt.Fprintln(w)
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d files failed", summary.Failed, summary.Files)
+	}
 	return nil
 }
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"character-tool/converter"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pre-compiled patterns for attack stat lines, such as "Melee Weapon Attack:
+// to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 1d8+3 slashing damage."
+// or, in the 2024 wording, "Melee Attack Roll: to hit: 1d20+5, reach 5 ft."
+var (
+	attackLabelRegex     = regexp.MustCompile(`(?i)\b(melee or ranged|melee|ranged) (?:(weapon|spell) attack|attack roll):`)
+	attackToHitRegex     = regexp.MustCompile(`to hit:\s*(\d*d\d+[+-]?\d*)`)
+	attackReachRegex     = regexp.MustCompile(`(?i)\breach (\d+) ?(?:ft\b\.?|feet\b)`)
+	attackRangeRegex     = regexp.MustCompile(`(?i)\brange (\d+)(?:/(\d+))? ?(?:ft\b\.?|feet\b)`)
+	attackTargetRegex    = regexp.MustCompile(`(?i)\b(?:target|creature|object)s?\b`)
+	attackHitRegex       = regexp.MustCompile(`\bHit:\s*`)
+	attackMissRegex      = regexp.MustCompile(`\bMiss:`)
+	attackDamageRegex    = regexp.MustCompile(`(?i)(?:\bplus\s+)?damage:\s*(\d*d\d+[+-]?\d*)(?:\s+(acid|bludgeoning|cold|fire|force|lightning|necrotic|piercing|poison|psychic|radiant|slashing|thunder)\b)?(?:\s+damage\b)?`)
+	attackVersatileRegex = regexp.MustCompile(`(?i),?\s*or\s+damage:\s*(\d*d\d+[+-]?\d*)(?:\s+\w+)?(?:\s+damage)?\s+if used with two hands[^.]*`)
+	attackRiderTrim      = regexp.MustCompile(`(?i)^(?:[\s,;.]|\b(?:and|or)\b)+|[\s,;.]+$`)
+)
+
+// Attack is the stat line of a weapon or spell attack, read from an
+// ability's description
+type Attack struct {
+	// Melee and Ranged are both set for "Melee or Ranged Weapon Attack"
+	Melee  bool
+	Ranged bool
+	// Spell is set for spell attacks and unset for weapon attacks
+	Spell bool
+	// Roll is set for the 2024 "Melee Attack Roll:" wording, which says
+	// neither weapon nor spell and leaves the targets out
+	Roll bool
+	// Bonus is the attack roll, such as "1d20+5"
+	Bonus string
+	// Reach, Range and LongRange are in feet; 0 when not given
+	Reach     int
+	Range     int
+	LongRange int
+	// Targets is who the attack can hit, such as "one target"
+	Targets string
+	// Damage lists the damage dealt on a hit, in order
+	Damage []DamagePart
+	// Versatile is the damage when used with two hands, if any
+	Versatile string
+	// Riders are the other effects in the Hit: sentence, such as "the
+	// target is grappled (escape DC 13)"; later sentences aren't riders
+	Riders []string
+}
+
+// DamagePart is one damage roll of an attack
+type DamagePart struct {
+	// Notation is normalized dice notation, such as "1d8+3"
+	Notation string
+	// Type is the damage type, such as "slashing"; empty if not given
+	Type string
+}
+
+// Kind returns the attack's label, such as "Melee Weapon Attack" or, for the
+// 2024 wording, "Melee Attack Roll"
+func (a *Attack) Kind() string {
+	kind := "Melee"
+	switch {
+	case a.Melee && a.Ranged:
+		kind = "Melee or Ranged"
+	case a.Ranged:
+		kind = "Ranged"
+	}
+	switch {
+	case a.Roll:
+		return kind + " Attack Roll"
+	case a.Spell:
+		return kind + " Spell Attack"
+	}
+	return kind + " Weapon Attack"
+}
+
+// ParseAttack reads the attack stat line from a description. It returns nil
+// if the description has no "Melee Weapon Attack:" or "Melee Attack Roll:"
+// style label, and warnings for the parts of a labelled attack that can't be
+// read.
+func ParseAttack(description string) (*Attack, []string) {
+	text := converter.StripMarkdown(description)
+	label := attackLabelRegex.FindStringSubmatchIndex(text)
+	if label == nil {
+		return nil, nil
+	}
+
+	kind := strings.ToLower(text[label[2]:label[3]])
+	attack := &Attack{
+		Melee:  strings.Contains(kind, "melee"),
+		Ranged: strings.Contains(kind, "ranged"),
+		Roll:   label[4] < 0,
+	}
+	if !attack.Roll {
+		attack.Spell = strings.EqualFold(text[label[4]:label[5]], "spell")
+	}
+	var warnings []string
+
+	// The stat line runs from the label to "Hit:", or to the end of the
+	// sentence if there is none. The Hit: effect is the rest of the
+	// paragraph up to any "Miss:".
+	text = text[label[1]:]
+	if end := strings.Index(text, "\n\n"); end >= 0 {
+		text = text[:end]
+	}
+	line, hit := sentences(text)[0], ""
+	if loc := attackHitRegex.FindStringIndex(text); loc != nil {
+		line, hit = text[:loc[0]], text[loc[1]:]
+		if miss := attackMissRegex.FindStringIndex(hit); miss != nil {
+			hit = hit[:miss[0]]
+		}
+	}
+
+	if match := attackToHitRegex.FindStringSubmatch(line); match == nil {
+		warnings = append(warnings, "attack has no to hit: roll")
+	} else if bonus, err := converter.ParseDiceNotation(match[1]); err != nil {
+		warnings = append(warnings, "attack has an invalid to hit: roll "+match[1])
+	} else {
+		attack.Bonus = bonus
+	}
+
+	if match := attackReachRegex.FindStringSubmatch(line); match != nil {
+		attack.Reach, _ = strconv.Atoi(match[1])
+	}
+	if match := attackRangeRegex.FindStringSubmatch(line); match != nil {
+		attack.Range, _ = strconv.Atoi(match[1])
+		attack.LongRange, _ = strconv.Atoi(match[2])
+	}
+	if attack.Reach == 0 && attack.Range == 0 {
+		warnings = append(warnings, "attack has no reach or range")
+	}
+
+	for part := range strings.SplitSeq(line, ",") {
+		part = strings.Trim(part, " .")
+		if attackTargetRegex.MatchString(part) && !attackReachRegex.MatchString(part) && !attackRangeRegex.MatchString(part) {
+			attack.Targets = part
+			break
+		}
+	}
+	if attack.Targets == "" && !attack.Roll {
+		warnings = append(warnings, "attack has no targets")
+	}
+
+	if hit == "" {
+		return attack, append(warnings, "attack has no Hit: effect")
+	}
+	warnings = append(warnings, parseHit(attack, hit)...)
+	return attack, warnings
+}
+
+// parseHit reads the damage, versatile damage and riders of a Hit: effect
+func parseHit(attack *Attack, hit string) []string {
+	var warnings []string
+
+	if match := attackVersatileRegex.FindStringSubmatch(hit); match != nil {
+		if notation, err := converter.ParseDiceNotation(match[1]); err == nil {
+			attack.Versatile = notation
+			hit = strings.Replace(hit, match[0], "", 1)
+		}
+	}
+
+	for _, groups := range attackDamageRegex.FindAllStringSubmatch(hit, -1) {
+		notation, err := converter.ParseDiceNotation(groups[1])
+		if err != nil {
+			warnings = append(warnings, "attack has an invalid damage: roll "+groups[1])
+			continue
+		}
+		attack.Damage = append(attack.Damage, DamagePart{Notation: notation, Type: strings.ToLower(groups[2])})
+	}
+
+	// Riders are the clauses around the damage in the Hit: sentence itself,
+	// such as ", and the target is grappled"
+	for _, clause := range attackDamageRegex.Split(sentences(hit)[0], -1) {
+		if rider := attackRiderTrim.ReplaceAllString(clause, ""); rider != "" {
+			attack.Riders = append(attack.Riders, rider)
+		}
+	}
+	if len(attack.Damage) == 0 && len(attack.Riders) == 0 {
+		warnings = append(warnings, "attack's Hit: effect has no damage")
+	}
+	return warnings
+}
+
+// sentences splits text after each ". " that is followed by a capital
+// letter, so abbreviations such as "10 ft. away" stay in one sentence
+func sentences(text string) []string {
+	var result []string
+	start := 0
+	for i := 0; i+2 < len(text); i++ {
+		if text[i] == '.' && text[i+1] == ' ' && text[i+2] >= 'A' && text[i+2] <= 'Z' {
+			result = append(result, text[start:i+1])
+			start = i + 2
+		}
+	}
+	return append(result, text[start:])
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAttack(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    *Attack
+		warnings    []string
+	}{
+		{
+			"melee weapon",
+			"Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 1d8+3 slashing damage.",
+			&Attack{Melee: true, Bonus: "1d20+5", Reach: 5, Targets: "one target", Damage: []DamagePart{{"1d8+3", "slashing"}}},
+			nil,
+		},
+		{
+			"ranged spell with long range",
+			"*Ranged Spell Attack:* to hit: 1d20+5, range 150/600 ft., one creature. *Hit:* damage: 1d10 fire damage.",
+			&Attack{Ranged: true, Spell: true, Bonus: "1d20+5", Range: 150, LongRange: 600, Targets: "one creature", Damage: []DamagePart{{"1d10", "fire"}}},
+			nil,
+		},
+		{
+			"melee or ranged with versatile damage",
+			"Melee or Ranged Weapon Attack: to hit: 1d20+4, reach 5 ft. or range 20/60 ft., one target. Hit: damage: 1d6+2 piercing damage, or damage: 1d8+2 piercing damage if used with two hands to make a melee attack.",
+			&Attack{Melee: true, Ranged: true, Bonus: "1d20+4", Reach: 5, Range: 20, LongRange: 60, Targets: "one target", Damage: []DamagePart{{"1d6+2", "piercing"}}, Versatile: "1d8+2"},
+			nil,
+		},
+		{
+			"extra damage and riders",
+			"Melee Weapon Attack: to hit: 1d20+6, reach 10 ft., one target. Hit: damage: 2d6+4 bludgeoning damage, and the target is pushed 10 ft. away, plus damage: 1d6 fire damage, and the target is grappled (escape DC 14). The target is frightened.",
+			&Attack{Melee: true, Bonus: "1d20+6", Reach: 10, Targets: "one target", Damage: []DamagePart{{"2d6+4", "bludgeoning"}, {"1d6", "fire"}}, Riders: []string{"the target is pushed 10 ft. away", "the target is grappled (escape DC 14)"}},
+			nil,
+		},
+		{
+			"later sentences are not riders",
+			"Melee Weapon Attack: to hit: 1d20+4, reach 5 ft., one target. Hit: damage: 2d4+2 piercing damage. If the target is a creature, it is knocked prone. See the rules for prone.",
+			&Attack{Melee: true, Bonus: "1d20+4", Reach: 5, Targets: "one target", Damage: []DamagePart{{"2d4+2", "piercing"}}},
+			nil,
+		},
+		{
+			"ends at the paragraph",
+			"Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 1d8+3 slashing damage.\n\nOn a critical hit, the target is knocked prone.",
+			&Attack{Melee: true, Bonus: "1d20+5", Reach: 5, Targets: "one target", Damage: []DamagePart{{"1d8+3", "slashing"}}},
+			nil,
+		},
+		{
+			"missing parts",
+			"Melee Weapon Attack: +5 to hit. The target takes a beating.",
+			&Attack{Melee: true},
+			[]string{"attack has no to hit: roll", "attack has no reach or range", "attack has no targets", "attack has no Hit: effect"},
+		},
+		{
+			"no damage",
+			"Ranged Weapon Attack: to hit: 1d20+4, range 30 ft., one target. Hit: damage: 9d7 force.",
+			&Attack{Ranged: true, Bonus: "1d20+4", Range: 30, Targets: "one target"},
+			[]string{"attack has an invalid damage: roll 9d7", "attack's Hit: effect has no damage"},
+		},
+		{
+			"2024 attack roll",
+			"*Melee Attack Roll:* to hit: 1d20+7, reach 5 ft. *Hit:* damage: 2d8+5 Slashing damage.",
+			&Attack{Melee: true, Roll: true, Bonus: "1d20+7", Reach: 5, Damage: []DamagePart{{"2d8+5", "slashing"}}},
+			nil,
+		},
+		{"not an attack", "You can see in dim light within 60 feet.", nil, nil},
+		{"roll without a label", "to hit: 1d20+5, damage: 1d8+3 slashing damage.", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attack, warnings := ParseAttack(tt.description)
+			if !reflect.DeepEqual(attack, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, attack)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("Expected warnings %q, got %q", tt.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestAttack_Kind(t *testing.T) {
+	tests := []struct {
+		attack   Attack
+		expected string
+	}{
+		{Attack{Melee: true}, "Melee Weapon Attack"},
+		{Attack{Ranged: true, Spell: true}, "Ranged Spell Attack"},
+		{Attack{Melee: true, Ranged: true}, "Melee or Ranged Weapon Attack"},
+		{Attack{Ranged: true, Roll: true}, "Ranged Attack Roll"},
+	}
+	for _, tt := range tests {
+		if kind := tt.attack.Kind(); kind != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, kind)
+		}
+	}
+}
+
+func TestParseMarkdown_AttackWarnings(t *testing.T) {
+	input := `## Actions
+
+**Longsword.** Melee Weapon Attack: to hit: 1d20+5, reach 5 ft., one target. Hit: damage: 1d8+3 slashing damage.
+
+**Claw.** Melee Weapon Attack: to hit: 1d20+5, one target. Hit: damage: 1d6+3 slashing damage.`
+
+	result, err := ParseMarkdown(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Actions[0].Attack == nil || result.Actions[0].Attack.Reach != 5 {
+		t.Errorf("Expected Longsword's attack with reach 5, got %+v", result.Actions[0].Attack)
+	}
+	expected := []Warning{{Type: Action, Ability: "Claw", Line: 5, Message: "attack has no reach or range"}}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Warnings)
+	}
+	if expected[0].String() != "[Actions] Claw: attack has no reach or range" {
+		t.Errorf("Expected a section prefixed warning, got %q", expected[0].String())
+	}
+}
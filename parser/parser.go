@@ -1,8 +1,14 @@
 package parser
 
 import (
+	"bytes"
+	"character-tool/converter"
 	"regexp"
 	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
 )
 
 // AbilityType represents the type of ability
@@ -15,11 +21,134 @@ const (
 	Reaction
 )
 
+// sectionOrder lists ability types in the order sections are output
+var sectionOrder = []AbilityType{Trait, Action, BonusAction, Reaction}
+
+// String returns the singular name of the ability type (e.g. "Bonus Action")
+func (t AbilityType) String() string {
+	switch t {
+	case Trait:
+		return "Trait"
+	case Action:
+		return "Action"
+	case BonusAction:
+		return "Bonus Action"
+	case Reaction:
+		return "Reaction"
+	default:
+		return "Unknown"
+	}
+}
+
+// SectionName returns the section heading for the ability type (e.g. "Bonus Actions")
+func (t AbilityType) SectionName() string {
+	switch t {
+	case Trait:
+		return "Traits"
+	case Action:
+		return "Actions"
+	case BonusAction:
+		return "Bonus Actions"
+	case Reaction:
+		return "Reactions"
+	default:
+		return "Unknown"
+	}
+}
+
+// Slug returns a file-name friendly form of the section name (e.g. "bonus-actions")
+func (t AbilityType) Slug() string {
+	return strings.ReplaceAll(strings.ToLower(t.SectionName()), " ", "-")
+}
+
+// NameStyle is the markdown an ability's name was written in
+type NameStyle int
+
+const (
+	// BoldName is **Name.**, with the period inside or outside the bold
+	BoldName NameStyle = iota
+	// ColonName is **Name:**, with the colon inside or outside the bold
+	ColonName
+	// HeadingName is a ### Name subheading followed by the description
+	HeadingName
+)
+
+// nameStyles maps the names accepted by ParseNameStyle to styles
+var nameStyles = map[string]NameStyle{
+	"bold":    BoldName,
+	"colon":   ColonName,
+	"heading": HeadingName,
+}
+
+// String returns the name of the style, as accepted by ParseNameStyle
+func (s NameStyle) String() string {
+	for name, style := range nameStyles {
+		if style == s {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// Format writes a name in the style, as it would appear in the input
+func (s NameStyle) Format(name string) string {
+	switch s {
+	case ColonName:
+		return "**" + name + ":**"
+	case HeadingName:
+		return "### " + name
+	default:
+		return "**" + name + ".**"
+	}
+}
+
+// ParseNameStyle returns the style with the given name (bold, colon or heading)
+func ParseNameStyle(name string) (NameStyle, bool) {
+	style, ok := nameStyles[strings.ToLower(strings.TrimSpace(name))]
+	return style, ok
+}
+
 // Ability represents a character ability, trait, action, etc.
 type Ability struct {
 	Name        string
 	Description string
 	Type        AbilityType
+	// Usage holds the limits split off the end of the name, such as
+	// (Recharge 5–6) or (3/Day)
+	Usage Usage
+	// Style is how the name was written; every style parses to the same
+	// Name and Description
+	Style NameStyle
+	// Line is the line of the input the ability starts on
+	Line int
+	// Attack is the ability's attack stat line, or nil if it has none
+	Attack *Attack
+	// RollText is the description the outputs convert: Description with
+	// 2024 stat-block wording and, with Options.InferRolls, rolls without
+	// keywords rewritten as roll keywords. Description itself stays as
+	// written in the input.
+	RollText string
+}
+
+// Warning is a problem found in an ability while parsing it
+type Warning struct {
+	Type    AbilityType
+	Ability string
+	Line    int
+	Message string
+	// Confidence is set on notes about rolls read with Options.InferRolls,
+	// which are information rather than problems
+	Confidence converter.Confidence
+}
+
+// String formats the warning as "[Section] Ability: message", like the
+// conversion warnings of the output formats. Plain text paragraphs have no
+// ability name, so theirs is "[Section] message".
+func (w Warning) String() string {
+	if w.Ability == "" {
+		return "[" + w.Type.SectionName() + "] " + w.Message
+	}
+	return "[" + w.Type.SectionName() + "] " + w.Ability + ": " + w.Message
 }
 
 // ParseResult contains all parsed abilities organized by type
@@ -28,145 +157,421 @@ type ParseResult struct {
 	Actions      []Ability
 	BonusActions []Ability
 	Reactions    []Ability
+	// Warnings are the parts of abilities that couldn't be read, such as
+	// an attack without a reach or range
+	Warnings []Warning
+	// Ruleset is the wording the document was read with, Rules2014 or
+	// Rules2024
+	Ruleset Ruleset
+}
+
+// Section holds the abilities of a single type in document order
+type Section struct {
+	Type      AbilityType
+	Abilities []Ability
+}
+
+// Sections returns every section of the result in output order
+// (Traits, Actions, Bonus Actions, Reactions), including empty ones
+func (r *ParseResult) Sections() []Section {
+	sections := make([]Section, 0, len(sectionOrder))
+	for _, abilityType := range sectionOrder {
+		sections = append(sections, Section{Type: abilityType, Abilities: r.Abilities(abilityType)})
+	}
+	return sections
 }
 
-// ParseMarkdown parses a markdown string and extracts character abilities
+// Abilities returns the abilities of the given type
+func (r *ParseResult) Abilities(abilityType AbilityType) []Ability {
+	switch abilityType {
+	case Trait:
+		return r.Traits
+	case Action:
+		return r.Actions
+	case BonusAction:
+		return r.BonusActions
+	case Reaction:
+		return r.Reactions
+	default:
+		return nil
+	}
+}
+
+// frontmatterRegex matches a YAML frontmatter block at the start of a document
+var frontmatterRegex = regexp.MustCompile(`\A---\n(?s:.*?)\n---(?:\n|\z)`)
+
+// ParseMarkdown parses a markdown string and extracts character abilities.
+// The document is read as CommonMark, so headings inside code blocks don't
+// start sections and subheadings don't break up the abilities around them.
+// Paragraphs, lists and other blocks after a named ability continue its
+// description, separated by blank lines, until the next named ability,
+// heading or thematic break. Names can be written as **Name.**, **Name:**
+// or a ### Name subheading; a subheading with nothing under it before the
+// next name is kept as a plain text paragraph. The ruleset is detected from
+// the document's wording.
 func ParseMarkdown(markdown string) (*ParseResult, error) {
+	return ParseMarkdownWith(markdown, Options{})
+}
+
+// Options change how ParseMarkdownWith reads a document
+type Options struct {
+	// Ruleset is the stat-block wording to read. With Rules2024, the 2024
+	// wording is read as roll keywords ("Melee Attack Roll: +5" as
+	// "Melee Attack Roll: to hit: 1d20+5"), so every output format
+	// converts it.
+	Ruleset Ruleset
+	// InferRolls reads rolls written without keywords, such as "+5 to
+	// hit", with converter.InferRolls. Each roll found is added to the
+	// warnings with its confidence.
+	InferRolls bool
+}
+
+// ParseMarkdownWith parses like ParseMarkdown, with options
+func ParseMarkdownWith(markdown string, opts Options) (*ParseResult, error) {
 	result := &ParseResult{
 		Traits:       []Ability{},
 		Actions:      []Ability{},
 		BonusActions: []Ability{},
 		Reactions:    []Ability{},
+		Ruleset:      opts.Ruleset.resolve(markdown),
 	}
 
 	if strings.TrimSpace(markdown) == "" {
 		return result, nil
 	}
 
-	// Split by ## headers
-	sections := splitBySections(markdown)
+	// Lines are counted from the top of the input, frontmatter included
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	frontmatter := frontmatterRegex.FindString(markdown)
+	firstLine := strings.Count(frontmatter, "\n") + 1
+	source := []byte(markdown[len(frontmatter):])
+	document := goldmark.DefaultParser().Parse(text.NewReader(source))
 
-	for sectionName, content := range sections {
-		abilityType, ok := getSectionType(sectionName)
-		if !ok {
-			// Skip unknown sections
+	// Blocks outside the four known sections are skipped. open is set while
+	// the last ability is named and can take continuation blocks.
+	var abilityType AbilityType
+	inSection, open := false, false
+	var abilities []Ability
+
+	for block := document.FirstChild(); block != nil; block = block.NextSibling() {
+		if heading, ok := block.(*ast.Heading); ok && heading.Level <= 2 {
+			if _, known := SectionType(headingText(heading, source)); inSection && !known && heading.Level == 2 && setextHeading(heading, source) {
+				// Without a blank line above it, a --- separator turns the
+				// ability before it into a heading. Read them as the
+				// paragraph and separator they were meant to be.
+				paragraph := paragraphOf(heading)
+				document.ReplaceChild(document, heading, paragraph)
+				document.InsertAfter(document, paragraph, ast.NewThematicBreak())
+				block = paragraph
+			} else {
+				// A level 1 heading ends the current section
+				abilityType, inSection = SectionType(headingText(heading, source))
+				inSection = inSection && heading.Level == 2
+				open = false
+				continue
+			}
+		}
+		if !inSection {
 			continue
 		}
 
-		abilities := parseAbilities(content, abilityType)
+		ability, ok := parseBlock(block, source, abilityType)
+		switch {
+		case !ok:
+			// A thematic break (---) ends the ability, so the next paragraph
+			// stands on its own
+			open = false
+		case ability.Name == "" && open:
+			last := &abilities[len(abilities)-1]
+			if last.Description != "" {
+				last.Description += "\n\n"
+			}
+			last.Description += ability.Description
+		default:
+			ability.Name, ability.Usage = splitUsage(ability.Name)
+			ability.Line = firstLine + lineOf(block, source)
+			abilities = append(abilities, ability)
+			open = ability.Name != ""
+		}
+	}
 
-		switch abilityType {
+	for _, ability := range abilities {
+		if ability.Style == HeadingName && ability.Description == "" {
+			// A subheading that only groups the abilities after it
+			ability.Name, ability.Description, ability.Usage, ability.Style = "", ability.Title(), Usage{}, BoldName
+		}
+		ability.RollText = ability.Description
+		if result.Ruleset == Rules2024 {
+			ability.RollText = converter.Convert2024(ability.RollText)
+		}
+		if opts.InferRolls {
+			var inferences []converter.Inference
+			ability.RollText, inferences = converter.InferRolls(ability.RollText)
+			for _, inference := range inferences {
+				result.Warnings = append(result.Warnings, Warning{Type: ability.Type, Ability: ability.Name, Line: ability.Line, Message: inference.String(), Confidence: inference.Confidence})
+			}
+		}
+		if ability.Name != "" {
+			var warnings []string
+			ability.Attack, warnings = ParseAttack(ability.RollText)
+			for _, warning := range warnings {
+				result.Warnings = append(result.Warnings, Warning{Type: ability.Type, Ability: ability.Name, Line: ability.Line, Message: warning})
+			}
+		}
+		switch ability.Type {
 		case Trait:
-			result.Traits = append(result.Traits, abilities...)
+			result.Traits = append(result.Traits, ability)
 		case Action:
-			result.Actions = append(result.Actions, abilities...)
+			result.Actions = append(result.Actions, ability)
 		case BonusAction:
-			result.BonusActions = append(result.BonusActions, abilities...)
+			result.BonusActions = append(result.BonusActions, ability)
 		case Reaction:
-			result.Reactions = append(result.Reactions, abilities...)
+			result.Reactions = append(result.Reactions, ability)
 		}
 	}
 
 	return result, nil
 }
 
-// splitBySections splits markdown by ## headers and returns a map of section name to content
-func splitBySections(markdown string) map[string]string {
-	sections := make(map[string]string)
+// SectionType maps section names to AbilityType. Matching is case-insensitive
+// and accepts hyphens or underscores in place of spaces ("bonus-actions").
+func SectionType(sectionName string) (AbilityType, bool) {
+	normalized := strings.ToLower(strings.TrimSpace(sectionName))
+	normalized = strings.NewReplacer("-", " ", "_", " ").Replace(normalized)
+
+	switch normalized {
+	case "traits":
+		return Trait, true
+	case "actions":
+		return Action, true
+	case "bonus actions":
+		return BonusAction, true
+	case "reactions":
+		return Reaction, true
+	default:
+		return 0, false
+	}
+}
 
-	// Regex to match ## Header
-	headerRegex := regexp.MustCompile(`(?m)^## (.+)$`)
-	matches := headerRegex.FindAllStringSubmatchIndex(markdown, -1)
+// parseBlock turns one block of a section into an ability. Paragraphs that
+// start with a bold name are named abilities, and subheadings name the
+// blocks after them; other blocks are kept as plain text paragraphs, as
+// written in the input.
+func parseBlock(block ast.Node, source []byte, abilityType AbilityType) (Ability, bool) {
+	var description string
 
-	if len(matches) == 0 {
-		return sections
+	switch block := block.(type) {
+	case *ast.ThematicBreak:
+		return Ability{}, false
+	case *ast.Heading:
+		// The description is filled in from the blocks that follow
+		if name := headingText(block, source); name != "" {
+			return Ability{Name: name, Type: abilityType, Style: HeadingName}, true
+		}
+		return Ability{}, false
+	case *ast.Paragraph:
+		if name, description, style, ok := splitName(block, source); ok {
+			return Ability{Name: name, Description: description, Type: abilityType, Style: style}, true
+		}
+		description = blockText(block, source)
+	default:
+		description = blockText(block, source)
 	}
 
-	for i, match := range matches {
-		headerEnd := match[1]
-		nameStart := match[2]
-		nameEnd := match[3]
+	if description == "" {
+		return Ability{}, false
+	}
+	return Ability{Description: description, Type: abilityType}, true
+}
 
-		sectionName := markdown[nameStart:nameEnd]
+// splitName splits a paragraph that starts with **Name.**, **Name**. or
+// **Name:** into its name, description and name style. The description must
+// not be empty.
+func splitName(paragraph *ast.Paragraph, source []byte) (string, string, NameStyle, bool) {
+	strong, ok := paragraph.FirstChild().(*ast.Emphasis)
+	lines := paragraph.Lines()
+	if !ok || strong.Level != 2 || lines.Len() == 0 {
+		return "", "", BoldName, false
+	}
 
-		// Content starts after the header line
-		contentStart := headerEnd
-		if contentStart < len(markdown) && markdown[contentStart] == '\n' {
-			contentStart++
-		}
+	start := lines.At(0).Start
+	stop := lines.At(lines.Len() - 1).Stop
+	if !bytes.HasPrefix(source[start:], []byte("**")) {
+		return "", "", BoldName, false
+	}
 
-		// Content ends at the next header or end of string
-		var contentEnd int
-		if i < len(matches)-1 {
-			contentEnd = matches[i+1][0]
-		} else {
-			contentEnd = len(markdown)
+	// The closing ** is the end of the first run of asterisks after the
+	// name's text, since nested emphasis closes first: **Keen *Senses***
+	nameEnd := start + 2
+	ast.Walk(strong, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if text, ok := node.(*ast.Text); ok && entering {
+			nameEnd = max(nameEnd, text.Segment.Stop)
 		}
+		return ast.WalkContinue, nil
+	})
+	closing := bytes.Index(source[nameEnd:stop], []byte("**"))
+	if closing < 0 {
+		return "", "", BoldName, false
+	}
+	closing += nameEnd
+	for closing+2 < stop && source[closing+2] == '*' {
+		closing++
+	}
 
-		content := strings.TrimSpace(markdown[contentStart:contentEnd])
-		sections[strings.TrimSpace(sectionName)] = content
+	name := strings.TrimSpace(string(source[start+2 : closing]))
+	description := string(source[closing+2 : stop])
+	style := BoldName
+	if strings.HasSuffix(name, ":") || strings.HasPrefix(description, ":") {
+		style = ColonName
+	}
+	name = strings.TrimSpace(strings.TrimRight(name, ".:"))
+	description = strings.TrimSpace(strings.TrimLeft(description, ".:"))
+	if name == "" || description == "" {
+		return "", "", BoldName, false
 	}
+	return name, description, style, true
+}
 
-	return sections
+// lineOf returns the zero-based line a block starts on
+func lineOf(block ast.Node, source []byte) int {
+	start, _ := blockRange(block, source)
+	if start < 0 {
+		return 0
+	}
+	return bytes.Count(source[:start], []byte("\n"))
 }
 
-// getSectionType maps section names to AbilityType
-func getSectionType(sectionName string) (AbilityType, bool) {
-	normalized := strings.ToLower(strings.TrimSpace(sectionName))
+// headingText returns the text of a heading without its # markers
+func headingText(heading *ast.Heading, source []byte) string {
+	var b strings.Builder
+	lines := heading.Lines()
+	for i := range lines.Len() {
+		line := lines.At(i)
+		b.Write(line.Value(source))
+	}
+	return strings.TrimSpace(b.String())
+}
 
-	switch normalized {
-	case "traits":
-		return Trait, true
-	case "actions":
-		return Action, true
-	case "bonus actions":
-		return BonusAction, true
-	case "reactions":
-		return Reaction, true
-	default:
-		return 0, false
+// setextHeading reports whether a heading is underlined with --- or ===
+// rather than written with # markers
+func setextHeading(heading *ast.Heading, source []byte) bool {
+	lines := heading.Lines()
+	if lines.Len() == 0 {
+		return false
 	}
+	start := lines.At(0).Start
+	lineStart := bytes.LastIndexByte(source[:start], '\n') + 1
+	return !bytes.Contains(source[lineStart:start], []byte("#"))
 }
 
-// parseAbilities extracts individual abilities from section content
-func parseAbilities(content string, abilityType AbilityType) []Ability {
-	abilities := []Ability{}
+// paragraphOf moves the text of a heading into a new paragraph
+func paragraphOf(heading *ast.Heading) *ast.Paragraph {
+	paragraph := ast.NewParagraph()
+	paragraph.SetLines(heading.Lines())
+	for child := heading.FirstChild(); child != nil; child = heading.FirstChild() {
+		paragraph.AppendChild(paragraph, child)
+	}
+	return paragraph
+}
 
-	if strings.TrimSpace(content) == "" {
-		return abilities
+// blockText returns the markdown of a block as written, from the start of
+// its first line to the end of its last, so list and quote markers and code
+// fences are kept
+func blockText(block ast.Node, source []byte) string {
+	start, stop := blockRange(block, source)
+	if start < 0 {
+		return ""
 	}
 
-	// Split by paragraph breaks to separate abilities
-	for paragraph := range strings.SplitSeq(content, "\n\n") {
-		paragraph = strings.TrimSpace(paragraph)
-		if paragraph == "" {
-			continue
+	start = bytes.LastIndexByte(source[:start], '\n') + 1
+	return strings.TrimSpace(string(source[start:stop]))
+}
+
+// blockRange returns the source range covered by the lines of a block and
+// the blocks inside it, or -1, -1 if it has none
+func blockRange(block ast.Node, source []byte) (int, int) {
+	start, stop := -1, -1
+	ast.Walk(block, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || node.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		lines := node.Lines()
+		if lines.Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+		first, last := lines.At(0).Start, lines.At(lines.Len()-1).Stop
+		if _, ok := node.(*ast.FencedCodeBlock); ok {
+			first, last = fenceRange(source, first, last)
 		}
+		if start < 0 || first < start {
+			start = first
+		}
+		stop = max(stop, last)
+		return ast.WalkContinue, nil
+	})
+	return start, stop
+}
+
+// fenceRange widens the content lines of a fenced code block to include its
+// opening fence and, if the block is closed, its closing fence
+func fenceRange(source []byte, start, stop int) (int, int) {
+	lineStart := bytes.LastIndexByte(source[:start], '\n') + 1
+	if lineStart > 0 {
+		start = bytes.LastIndexByte(source[:lineStart-1], '\n') + 1
+	}
 
-		// Match **Name.** Description or **Name**. Description pattern
-		// Supports period inside or outside bold markers
-		abilityRegex := regexp.MustCompile(`^\*\*([^*]+?)\.?\*\*\.?\s*(.+)$`)
-		match := abilityRegex.FindStringSubmatch(paragraph)
+	end := bytes.IndexByte(source[stop:], '\n')
+	if end < 0 {
+		end = len(source) - stop
+	}
+	closing := bytes.TrimSpace(source[stop : stop+end])
+	if bytes.HasPrefix(closing, []byte("```")) || bytes.HasPrefix(closing, []byte("~~~")) {
+		stop += end
+	}
+	return start, stop
+}
 
-		if len(match) >= 3 {
-			// Named ability
-			name := strings.TrimSpace(match[1])
-			description := strings.TrimSpace(match[2])
+// Block is one paragraph or list of a description
+type Block struct {
+	// Text is the paragraph as written; empty for lists
+	Text string
+	// Items are the list items as written, without their markers
+	Items []string
+	// Ordered is set for numbered lists
+	Ordered bool
+}
 
-			abilities = append(abilities, Ability{
-				Name:        name,
-				Description: description,
-				Type:        abilityType,
-			})
-		} else {
-			// Plain text paragraph (no name)
-			abilities = append(abilities, Ability{
-				Name:        "",
-				Description: paragraph,
-				Type:        abilityType,
-			})
+// Blocks splits a description into its paragraphs and lists, for formats
+// that lay them out separately. Code blocks and quotes are kept as
+// paragraphs, as written.
+func Blocks(description string) []Block {
+	source := []byte(description)
+	document := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	var blocks []Block
+	for node := document.FirstChild(); node != nil; node = node.NextSibling() {
+		list, ok := node.(*ast.List)
+		if !ok {
+			if text := blockText(node, source); text != "" {
+				blocks = append(blocks, Block{Text: text})
+			}
+			continue
 		}
+
+		block := Block{Ordered: list.IsOrdered()}
+		for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+			block.Items = append(block.Items, itemText(item, source))
+		}
+		blocks = append(blocks, block)
 	}
+	return blocks
+}
 
-	return abilities
+// itemText returns the text of a list item as written, without its marker
+func itemText(item ast.Node, source []byte) string {
+	start, stop := blockRange(item, source)
+	if start < 0 {
+		return ""
+	}
+	return strings.TrimSpace(string(source[start:stop]))
 }
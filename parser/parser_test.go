@@ -1,6 +1,11 @@
 package parser
 
 import (
+	"character-tool/converter"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -207,6 +212,45 @@ func TestParseMarkdown_PeriodPlacement(t *testing.T) {
 	}
 }
 
+func TestParseMarkdown_SeparatorWithoutBlankLine(t *testing.T) {
+	input := `## Actions
+
+**Wild Shape.** You assume the shape of a beast.
+
+A plain paragraph.
+---
+**Bite.** to hit: 1d20+4, damage: 1d6+2 piercing damage.
+---
+**Claw.** to hit: 1d20+4, damage: 1d4+2 slashing damage.
+
+## Reactions
+---
+
+**Parry.** You add 2 to your AC.`
+
+	result, err := ParseMarkdown(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, action := range result.Actions {
+		names = append(names, action.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Wild Shape", "Bite", "Claw"}) {
+		t.Fatalf("Expected Wild Shape, Bite and Claw, got %+v", result.Actions)
+	}
+	if result.Actions[0].Description != "You assume the shape of a beast.\n\nA plain paragraph." {
+		t.Errorf("Expected the plain paragraph to continue Wild Shape, got %q", result.Actions[0].Description)
+	}
+	if result.Actions[1].Line != 7 || result.Actions[1].Description != "to hit: 1d20+4, damage: 1d6+2 piercing damage." {
+		t.Errorf("Expected Bite on line 7, got %+v", result.Actions[1])
+	}
+	if len(result.Reactions) != 1 || result.Reactions[0].Name != "Parry" {
+		t.Errorf("Expected the separator under a section heading to be skipped, got %+v", result.Reactions)
+	}
+}
+
 func TestParseMarkdown_PlainTextParagraphs(t *testing.T) {
 	input := `## Traits
 
@@ -214,6 +258,8 @@ This character has a mysterious background that grants them unique abilities.
 
 **Darkvision.** You can see in dim light within 60 feet.
 
+---
+
 Due to their elven heritage, they also gain the following benefits.
 
 **Fey Ancestry.** You have advantage on saving throws against being charmed.`
@@ -254,3 +300,322 @@ Due to their elven heritage, they also gain the following benefits.
 		t.Errorf("Expected fourth trait name 'Fey Ancestry', got '%s'", result.Traits[3].Name)
 	}
 }
+
+func TestParseResult_SectionsInOrder(t *testing.T) {
+	input := `## Reactions
+
+**Parry.** Add 2 to your AC.
+
+## Traits
+
+**Pack Tactics.** You have advantage on attack rolls.`
+
+	result, err := ParseMarkdown(input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sections := result.Sections()
+	expected := []struct {
+		name  string
+		slug  string
+		count int
+	}{
+		{"Traits", "traits", 1},
+		{"Actions", "actions", 0},
+		{"Bonus Actions", "bonus-actions", 0},
+		{"Reactions", "reactions", 1},
+	}
+
+	if len(sections) != len(expected) {
+		t.Fatalf("Expected %d sections, got %d", len(expected), len(sections))
+	}
+
+	for i, exp := range expected {
+		if sections[i].Type.SectionName() != exp.name {
+			t.Errorf("Expected section %d to be %s, got %s", i, exp.name, sections[i].Type.SectionName())
+		}
+		if sections[i].Type.Slug() != exp.slug {
+			t.Errorf("Expected slug %s, got %s", exp.slug, sections[i].Type.Slug())
+		}
+		if len(sections[i].Abilities) != exp.count {
+			t.Errorf("Expected %d abilities in %s, got %d", exp.count, exp.name, len(sections[i].Abilities))
+		}
+	}
+}
+
+func TestSectionType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected AbilityType
+		ok       bool
+	}{
+		{"Traits", Trait, true},
+		{"actions", Action, true},
+		{"Bonus Actions", BonusAction, true},
+		{"bonus-actions", BonusAction, true},
+		{"bonus_actions", BonusAction, true},
+		{" REACTIONS ", Reaction, true},
+		{"Spells", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, ok := SectionType(tt.input)
+			if ok != tt.ok || result != tt.expected {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.expected, tt.ok, result, ok)
+			}
+		})
+	}
+}
+
+// corpusAbility is an ability in a testdata/*.json file, which lists the
+// expected abilities of the matching .md file by section name
+type corpusAbility struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// TestParseMarkdown_Corpus parses each document in testdata, with both LF and
+// CRLF line endings, and compares it with the expected abilities
+func TestParseMarkdown_Corpus(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.md")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected corpus documents, got %v, %v", files, err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedJSON, err := os.ReadFile(strings.TrimSuffix(file, ".md") + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected map[string][]corpusAbility
+		if err := json.Unmarshal(expectedJSON, &expected); err != nil {
+			t.Fatalf("Failed to read expected abilities for %s: %v", file, err)
+		}
+
+		for lineEnding, markdown := range map[string]string{
+			"LF":   string(content),
+			"CRLF": strings.ReplaceAll(string(content), "\n", "\r\n"),
+		} {
+			t.Run(filepath.Base(file)+"/"+lineEnding, func(t *testing.T) {
+				result, err := ParseMarkdown(markdown)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				actual := map[string][]corpusAbility{}
+				for _, section := range result.Sections() {
+					for _, ability := range section.Abilities {
+						if ability.Type != section.Type {
+							t.Errorf("Expected %q to be a %s, got %s", ability.Name, section.Type, ability.Type)
+						}
+						actual[section.Type.SectionName()] = append(actual[section.Type.SectionName()], corpusAbility{ability.Name, ability.Description})
+					}
+				}
+
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("Expected:\n%+v\nGot:\n%+v", expected, actual)
+				}
+			})
+		}
+	}
+}
+
+func TestParseMarkdown_ContinuationParagraphs(t *testing.T) {
+	input := `## Actions
+
+**Wild Shape.** You assume the shape of a beast.
+
+You can stay in the shape for an hour.
+- Wolf
+- Brown Bear
+
+---
+
+A plain paragraph after the separator.
+
+**Bite.** to hit: 1d20+4, damage: 1d6+2 piercing damage.`
+
+	result, err := ParseMarkdown(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Actions) != 3 {
+		t.Fatalf("Expected 3 actions, got %d: %+v", len(result.Actions), result.Actions)
+	}
+	expected := "You assume the shape of a beast.\n\nYou can stay in the shape for an hour.\n\n- Wolf\n- Brown Bear"
+	if result.Actions[0].Name != "Wild Shape" || result.Actions[0].Description != expected {
+		t.Errorf("Expected Wild Shape with its paragraphs and list, got %+v", result.Actions[0])
+	}
+	if result.Actions[1].Name != "" || result.Actions[1].Description != "A plain paragraph after the separator." {
+		t.Errorf("Expected a plain paragraph after the separator, got %+v", result.Actions[1])
+	}
+}
+
+func TestParseMarkdown_NameStyles(t *testing.T) {
+	input := `---
+title: Styles
+---
+## Actions
+
+**Bite.** to hit: 1d20+4, damage: 1d6+2 piercing damage.
+
+**Claw:** to hit: 1d20+4, damage: 1d4+2 slashing damage.
+
+### Tail
+
+to hit: 1d20+4, damage: 1d8+2 bludgeoning damage.`
+
+	result, err := ParseMarkdown(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name  string
+		style NameStyle
+		line  int
+	}{
+		{"Bite", BoldName, 6},
+		{"Claw", ColonName, 8},
+		{"Tail", HeadingName, 10},
+	}
+	if len(result.Actions) != len(expected) {
+		t.Fatalf("Expected %d actions, got %d: %+v", len(expected), len(result.Actions), result.Actions)
+	}
+	for i, want := range expected {
+		ability := result.Actions[i]
+		if ability.Name != want.name || ability.Style != want.style || ability.Line != want.line {
+			t.Errorf("Expected %s (%s) on line %d, got %s (%s) on line %d", want.name, want.style, want.line, ability.Name, ability.Style, ability.Line)
+		}
+		if !strings.HasPrefix(ability.Description, "to hit: 1d20+4") {
+			t.Errorf("Expected %s's description to start with its attack, got %q", want.name, ability.Description)
+		}
+	}
+}
+
+func TestParseNameStyle(t *testing.T) {
+	for _, style := range []NameStyle{BoldName, ColonName, HeadingName} {
+		parsed, ok := ParseNameStyle(style.String())
+		if !ok || parsed != style {
+			t.Errorf("Expected %q to parse as itself, got %v, %v", style, parsed, ok)
+		}
+	}
+	if _, ok := ParseNameStyle("italic"); ok {
+		t.Errorf("Expected an unknown style to be rejected")
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	description := "You assume the shape of a beast\nyou have seen.\n\n- Wolf\n- Brown\n  Bear\n\n1. First\n2. Second\n\n> Quoted"
+
+	expected := []Block{
+		{Text: "You assume the shape of a beast\nyou have seen."},
+		{Items: []string{"Wolf", "Brown\n  Bear"}},
+		{Items: []string{"First", "Second"}, Ordered: true},
+		{Text: "> Quoted"},
+	}
+	if blocks := Blocks(description); !reflect.DeepEqual(blocks, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, blocks)
+	}
+
+	if blocks := Blocks("One paragraph."); !reflect.DeepEqual(blocks, []Block{{Text: "One paragraph."}}) {
+		t.Errorf("Expected a single paragraph, got %+v", blocks)
+	}
+}
+
+func TestParseMarkdownWith_Ruleset(t *testing.T) {
+	input := `## Actions
+
+**Rend.** *Melee Attack Roll:* +7, reach 5 ft. *Hit:* 14 (2d8 + 5) Slashing damage.`
+	converted := "*Melee Attack Roll:* to hit: 1d20+7, reach 5 ft. *Hit:* damage: 2d8+5 Slashing damage."
+
+	tests := []struct {
+		name        string
+		input       string
+		ruleset     Ruleset
+		expected    Ruleset
+		description string
+	}{
+		{"detected", input, AutoRuleset, Rules2024, converted},
+		{"forced 2014", input, Rules2014, Rules2014, "*Melee Attack Roll:* +7, reach 5 ft. *Hit:* 14 (2d8 + 5) Slashing damage."},
+		{"forced 2024", "## Actions\n\n**Burn.** The target takes 7 (2d6) Fire damage.", Rules2024, Rules2024, "The target takes damage: 2d6 Fire damage."},
+		{"2014 wording", "## Actions\n\n**Burn.** The target takes 7 (2d6) Fire damage.", AutoRuleset, Rules2014, "The target takes 7 (2d6) Fire damage."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseMarkdownWith(tt.input, Options{Ruleset: tt.ruleset})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Ruleset != tt.expected {
+				t.Errorf("Expected ruleset %v, got %v", tt.expected, result.Ruleset)
+			}
+			if text := result.Actions[0].RollText; text != tt.description {
+				t.Errorf("Expected %q, got %q", tt.description, text)
+			}
+			if !strings.Contains(tt.input, result.Actions[0].Description) {
+				t.Errorf("Expected the description as written, got %q", result.Actions[0].Description)
+			}
+		})
+	}
+
+	result, _ := ParseMarkdown(input)
+	if attack := result.Actions[0].Attack; attack == nil || attack.Bonus != "1d20+7" || len(result.Warnings) != 0 {
+		t.Errorf("Expected a 2024 attack with bonus 1d20+7 and no warnings, got %+v and %v", attack, result.Warnings)
+	}
+}
+
+func TestParseRuleset(t *testing.T) {
+	for _, name := range []string{"auto", "2014", "2024"} {
+		ruleset, ok := ParseRuleset(name)
+		if !ok || ruleset.String() != name {
+			t.Errorf("Expected %q to round-trip, got %v (%v)", name, ruleset, ok)
+		}
+	}
+	if _, ok := ParseRuleset("2023"); ok {
+		t.Error("Expected 2023 to be rejected")
+	}
+}
+
+func TestParseMarkdownWith_InferRolls(t *testing.T) {
+	input := `## Actions
+
+**Scimitar.** Melee Weapon Attack: +4 to hit, reach 5 ft., one target. Hit: 5 (1d6 + 2) slashing damage.
+
+Then roll 1d6.`
+
+	result, err := ParseMarkdownWith(input, Options{InferRolls: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scimitar := result.Actions[0]
+	if !strings.Contains(scimitar.Description, "+4 to hit") || !strings.Contains(scimitar.RollText, "to hit: 1d20+4") {
+		t.Errorf("Expected the description as written and the roll text inferred, got %q and %q", scimitar.Description, scimitar.RollText)
+	}
+	if attack := scimitar.Attack; attack == nil || attack.Bonus != "1d20+4" || len(attack.Damage) != 1 {
+		t.Errorf("Expected the inferred attack to be read, got %+v", attack)
+	}
+	expected := []Warning{
+		{Type: Action, Ability: "Scimitar", Line: 3, Message: `read "+4 to hit" as to hit: 1d20+4 (high confidence)`, Confidence: converter.HighConfidence},
+		{Type: Action, Ability: "Scimitar", Line: 3, Message: `read "5 (1d6 + 2) slashing damage" as damage: 1d6+2 (high confidence)`, Confidence: converter.HighConfidence},
+		{Type: Action, Ability: "Scimitar", Line: 3, Message: `left "1d6" as text (low confidence: no roll type in the words around it)`, Confidence: converter.LowConfidence},
+	}
+	if !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Warnings)
+	}
+
+	// Without the option the same text has no rolls
+	result, _ = ParseMarkdown(input)
+	if !strings.Contains(result.Actions[0].Description, "+4 to hit") || len(result.Warnings) != 1 {
+		t.Errorf("Expected the text left alone with one attack warning, got %q and %v", result.Actions[0].Description, result.Warnings)
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"character-tool/converter"
+	"strings"
+)
+
+// Ruleset is the edition whose stat-block wording a document is read with
+type Ruleset int
+
+const (
+	// AutoRuleset reads a document with the 2024 wording if it uses it
+	// anywhere, and with the 2014 wording otherwise
+	AutoRuleset Ruleset = iota
+	// Rules2014 reads only the roll keywords, such as "to hit: 1d20+5"
+	Rules2014
+	// Rules2024 also reads the 2024 Monster Manual's wording, such as
+	// "Melee Attack Roll: +5" and "8 (1d8 + 3) Slashing damage"
+	Rules2024
+)
+
+// rulesets maps the names accepted by ParseRuleset to rulesets
+var rulesets = map[string]Ruleset{
+	"auto": AutoRuleset,
+	"2014": Rules2014,
+	"2024": Rules2024,
+}
+
+// String returns the name of the ruleset, as accepted by ParseRuleset
+func (r Ruleset) String() string {
+	for name, ruleset := range rulesets {
+		if ruleset == r {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// ParseRuleset returns the ruleset with the given name (auto, 2014 or 2024)
+func ParseRuleset(name string) (Ruleset, bool) {
+	ruleset, ok := rulesets[strings.ToLower(strings.TrimSpace(name))]
+	return ruleset, ok
+}
+
+// resolve picks the ruleset for a document, detecting it when r is AutoRuleset
+func (r Ruleset) resolve(markdown string) Ruleset {
+	if r != AutoRuleset {
+		return r
+	}
+	if converter.Uses2024Wording(markdown) {
+		return Rules2024
+	}
+	return Rules2014
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pre-compiled patterns for the usage parenthetical after an ability name
+var (
+	usageGroupRegex     = regexp.MustCompile(`^(.*\S)\s*\(([^()]+)\)$`)
+	rechargeRegex       = regexp.MustCompile(`(?i)^recharge\s+([1-6])(?:\s*[-–—]\s*6)?$`)
+	rechargeRestRegex   = regexp.MustCompile(`(?i)^recharges\s+after\s+an?\s+(short rest|long rest|short or long rest)$`)
+	usesPerRegex        = regexp.MustCompile(`(?i)^([1-9]\d*)\s*/\s*(day|short rest|long rest|short or long rest|turn|round)(\s+each)?$`)
+	resourceCostRegex   = regexp.MustCompile(`(?i)^(?:costs\s+([1-9]\d*)\s+(\S.*)|([1-9]\d*)\s+(\S.*\bpoints?))$`)
+	usageSeparatorRegex = regexp.MustCompile(`\s*[;,]\s*`)
+)
+
+// Usage is how often an ability can be used and what it costs, taken from a
+// parenthetical after its name such as (Recharge 5–6), (3/Day) or
+// (Costs 2 Actions)
+type Usage struct {
+	// Recharge is the lowest d6 roll that recharges the ability: 5 for
+	// Recharge 5–6, 0 if it doesn't recharge
+	Recharge int
+	// Uses is how many times the ability can be used each Per
+	Uses int
+	// Per is "Day", "Short Rest", "Long Rest", "Short or Long Rest", "Turn"
+	// or "Round"
+	Per string
+	// Each is set for (3/Day each), where every listed spell or option has
+	// its own Uses
+	Each bool
+	// Cost is how much of Resource one use costs: 2 for Costs 2 Actions
+	Cost     int
+	Resource string
+}
+
+// IsZero reports whether the ability has no usage limits or cost
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// RechargeText returns "Recharge 5–6", or "Recharge 6" when only a 6 recharges
+// it, or "" if the ability doesn't recharge
+func (u Usage) RechargeText() string {
+	switch {
+	case u.Recharge == 0:
+		return ""
+	case u.Recharge == 6:
+		return "Recharge 6"
+	default:
+		return fmt.Sprintf("Recharge %d–6", u.Recharge)
+	}
+}
+
+// Parts returns each part of the usage in its standard wording, such as
+// "Recharge 5–6", "3/Day", "1/Day each" and "Costs 2 Actions"
+func (u Usage) Parts() []string {
+	var parts []string
+	if text := u.RechargeText(); text != "" {
+		parts = append(parts, text)
+	}
+	if u.Uses > 0 {
+		part := fmt.Sprintf("%d/%s", u.Uses, u.Per)
+		if u.Each {
+			part += " each"
+		}
+		parts = append(parts, part)
+	}
+	if u.Cost > 0 {
+		parts = append(parts, fmt.Sprintf("Costs %d %s", u.Cost, u.Resource))
+	}
+	return parts
+}
+
+// String returns the usage as written after a name, without the parentheses:
+// "Recharge 5–6", "1/Short Rest" or "3/Day; Costs 2 Actions"
+func (u Usage) String() string {
+	return strings.Join(u.Parts(), "; ")
+}
+
+// Title returns the ability's name followed by its usage in parentheses, as
+// it is shown in every output format: "Breath Weapon (Recharge 5–6)"
+func (a Ability) Title() string {
+	if a.Usage.IsZero() || a.Name == "" {
+		return a.Name
+	}
+	return a.Name + " (" + a.Usage.String() + ")"
+}
+
+// splitUsage removes usage parentheticals from the end of a name and returns
+// the name and the usage. Parentheticals that aren't all usage, such as
+// (Bear Form), stay part of the name.
+func splitUsage(name string) (string, Usage) {
+	var usage Usage
+	for {
+		match := usageGroupRegex.FindStringSubmatch(name)
+		if match == nil {
+			return name, usage
+		}
+		parsed, ok := parseUsage(match[2], usage)
+		if !ok {
+			return name, usage
+		}
+		name, usage = match[1], parsed
+	}
+}
+
+// parseUsage adds the parts of one parenthetical, separated by ";" or ",",
+// to usage. It fails if any part isn't usage.
+func parseUsage(text string, usage Usage) (Usage, bool) {
+	for _, part := range usageSeparatorRegex.Split(strings.TrimSpace(text), -1) {
+		if match := rechargeRegex.FindStringSubmatch(part); match != nil {
+			usage.Recharge, _ = strconv.Atoi(match[1])
+		} else if match := rechargeRestRegex.FindStringSubmatch(part); match != nil {
+			usage.Uses, usage.Per = 1, titleCase(match[1])
+		} else if match := usesPerRegex.FindStringSubmatch(part); match != nil {
+			usage.Uses, _ = strconv.Atoi(match[1])
+			usage.Per = titleCase(match[2])
+			usage.Each = match[3] != ""
+		} else if match := resourceCostRegex.FindStringSubmatch(part); match != nil {
+			cost, resource := match[1], match[2]
+			if cost == "" {
+				cost, resource = match[3], match[4]
+			}
+			usage.Cost, _ = strconv.Atoi(cost)
+			usage.Resource = titleCase(resource)
+		} else {
+			return usage, false
+		}
+	}
+	return usage, true
+}
+
+// titleCase capitalizes each word except "or": "short or long rest" becomes
+// "Short or Long Rest"
+func titleCase(text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	for i, word := range words {
+		if word != "or" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,77 @@
+package parser
+
+import "testing"
+
+func TestSplitUsage(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		usage    Usage
+		rendered string
+	}{
+		{"Breath Weapon (Recharge 5–6)", "Breath Weapon", Usage{Recharge: 5}, "Recharge 5–6"},
+		{"Breath Weapon (Recharge 5-6)", "Breath Weapon", Usage{Recharge: 5}, "Recharge 5–6"},
+		{"Lightning Breath (recharge 6)", "Lightning Breath", Usage{Recharge: 6}, "Recharge 6"},
+		{"Misty Step (3/Day)", "Misty Step", Usage{Uses: 3, Per: "Day"}, "3/Day"},
+		{"Second Wind (1/short rest)", "Second Wind", Usage{Uses: 1, Per: "Short Rest"}, "1/Short Rest"},
+		{"Bardic Inspiration (Recharges after a Short or Long Rest)", "Bardic Inspiration", Usage{Uses: 1, Per: "Short or Long Rest"}, "1/Short or Long Rest"},
+		{"Wing Attack (Costs 2 Actions)", "Wing Attack", Usage{Cost: 2, Resource: "Actions"}, "Costs 2 Actions"},
+		{"Stunning Strike (1 Ki Point)", "Stunning Strike", Usage{Cost: 1, Resource: "Ki Point"}, "Costs 1 Ki Point"},
+		{"Teleport (Recharge 4–6; 1/Day)", "Teleport", Usage{Recharge: 4, Uses: 1, Per: "Day"}, "Recharge 4–6; 1/Day"},
+		{"Change Shape (Bear Form) (1/Day)", "Change Shape (Bear Form)", Usage{Uses: 1, Per: "Day"}, "1/Day"},
+		{"Change Shape (Bear Form)", "Change Shape (Bear Form)", Usage{}, ""},
+		{"Spellcasting (3/Day each)", "Spellcasting", Usage{Uses: 3, Per: "Day", Each: true}, "3/Day each"},
+		{"Spellcasting (1/day  Each)", "Spellcasting", Usage{Uses: 1, Per: "Day", Each: true}, "1/Day each"},
+		{"Spellcasting (3/Day every)", "Spellcasting (3/Day every)", Usage{}, ""},
+		{"Fire Breath (0/Day)", "Fire Breath (0/Day)", Usage{}, ""},
+		{"Wing Attack (Costs 0 Actions)", "Wing Attack (Costs 0 Actions)", Usage{}, ""},
+		{"Stunning Strike (0 Ki Points)", "Stunning Strike (0 Ki Points)", Usage{}, ""},
+		{"Longsword", "Longsword", Usage{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, usage := splitUsage(tt.input)
+			if name != tt.name || usage != tt.usage {
+				t.Errorf("Expected %q with %+v, got %q with %+v", tt.name, tt.usage, name, usage)
+			}
+			if usage.String() != tt.rendered {
+				t.Errorf("Expected usage %q, got %q", tt.rendered, usage.String())
+			}
+		})
+	}
+}
+
+func TestParseMarkdown_Usage(t *testing.T) {
+	input := `## Actions
+
+**Fire Breath (Recharge 5-6).** The dragon exhales fire.
+
+### Wing Attack (Costs 2 Actions)
+
+The dragon beats its wings.
+
+**Bite.** to hit: 1d20+6, damage: 2d10+4 piercing damage.`
+
+	result, err := ParseMarkdown(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name  string
+		title string
+	}{
+		{"Fire Breath", "Fire Breath (Recharge 5–6)"},
+		{"Wing Attack", "Wing Attack (Costs 2 Actions)"},
+		{"Bite", "Bite"},
+	}
+	if len(result.Actions) != len(expected) {
+		t.Fatalf("Expected %d actions, got %d: %+v", len(expected), len(result.Actions), result.Actions)
+	}
+	for i, want := range expected {
+		if result.Actions[i].Name != want.name || result.Actions[i].Title() != want.title {
+			t.Errorf("Expected %q titled %q, got %q titled %q", want.name, want.title, result.Actions[i].Name, result.Actions[i].Title())
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"character-tool/batch"
+	"character-tool/converter"
+	"character-tool/render"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var previewCmd = &cobra.Command{
+	Use:   "preview [files, directories or globs...]",
+	Short: "Write a standalone HTML stat-block preview of the D&D Beyond output",
+	Long: `Renders each character as a stat block in a standalone HTML file so you can
+check the output before pasting it into D&D Beyond. Spell tags are shown as
+links and rollables as buttons showing their display value and dice notation.
+
+The file is named after the input (fighter.md → fighter.html) and written to
+the output directory, or next to the input with --vault-mode.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputs, err := collectInputs(args)
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+		return runPreview(inputs, outputDir, verbose, vaultMode, workers)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+}
+
+func runPreview(inputs []string, outputDir string, verbose, vaultMode bool, workers int) error {
+	files, outputDirs, err := resolveInputs(inputs, outputDir, vaultMode, nil)
+	if err != nil {
+		return err
+	}
+
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		return fmt.Errorf("failed to load spell list: %w", err)
+	}
+
+	results := batch.Run(files, workers, func(input string) batch.Result {
+		return previewFile(input, outputDirs[input], spells)
+	})
+
+	if len(results) == 1 {
+		if results[0].Err != nil {
+			return results[0].Err
+		}
+		printResult(os.Stdout, results[0], verbose)
+		return nil
+	}
+
+	return printBatchSummary(os.Stdout, results, verbose)
+}
+
+// previewFile renders a single input to an HTML file
+func previewFile(inputFile, outputDir string, spells map[string]bool) batch.Result {
+	renderer, _ := render.Get("html")
+	return processFile(inputFile, outputDir, spells, outputOptions{renderer: renderer})
+}
@@ -0,0 +1,168 @@
+package render
+
+import (
+	"character-tool/export"
+	"character-tool/formatter"
+	"character-tool/parser"
+	"character-tool/vtt"
+	"fmt"
+	"strings"
+)
+
+// documentRenderer writes the whole input to a single file named after the
+// input's stem
+type documentRenderer struct {
+	name      string
+	extension string
+	// build returns the document without a trailing newline, plus warnings
+	build func(doc Document, opts Options) (string, []string, error)
+}
+
+func (r documentRenderer) Name() string {
+	return r.name
+}
+
+// Suffix returns the ending of the file name, such as ".homebrewery.md"
+func (r documentRenderer) Suffix() string {
+	return r.extension
+}
+
+func (r documentRenderer) Render(doc Document, opts Options) (Output, error) {
+	text, warnings, err := r.build(doc, opts)
+	output := Output{Warnings: warnings}
+	if err != nil {
+		return output, err
+	}
+
+	output.Abilities = abilityCount(doc.Result)
+	output.Stdout = text
+	output.Files = []File{{Name: doc.Stem + r.extension, Content: text + "\n", Abilities: output.Abilities}}
+	return output, nil
+}
+
+// roll20Renderer writes Roll20 macros as text and as an importable character
+// JSON. Only the macros are printed with --stdout.
+type roll20Renderer struct{}
+
+func (roll20Renderer) Name() string {
+	return "roll20"
+}
+
+func (roll20Renderer) Render(doc Document, opts Options) (Output, error) {
+	character, warnings, err := export.BuildRoll20Character(doc.Result, doc.Spells, doc.Title)
+	output := Output{Warnings: warnings}
+	if err != nil {
+		return output, fmt.Errorf("failed to build Roll20 macros: %w", err)
+	}
+
+	encoded, err := export.FormatRoll20JSON(character)
+	if err != nil {
+		return output, fmt.Errorf("failed to encode Roll20 character: %w", err)
+	}
+
+	macros := export.FormatRoll20Macros(character)
+	output.Abilities = len(character.Abilities)
+	output.Stdout = macros
+	output.Files = []File{
+		{Name: doc.Stem + ".roll20.txt", Content: macros + "\n", Abilities: output.Abilities},
+		{Name: doc.Stem + ".roll20.json", Content: encoded + "\n", Abilities: output.Abilities},
+	}
+	return output, nil
+}
+
+// buildJSON renders the structured JSON document. Conversion diagnostics are
+// also reported as warnings, prefixed with their section name; the parse
+// warnings before them are already reported with the parse result.
+func buildJSON(doc Document, opts Options) (string, []string, error) {
+	jsonDoc, err := formatter.BuildJSONDocument(doc.Result, doc.Spells, opts.KeepMarkdown)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to build JSON: %w", err)
+	}
+	jsonDoc.Source = doc.Source
+
+	var warnings []string
+	for _, diagnostic := range jsonDoc.Diagnostics[len(doc.Result.Warnings):] {
+		sectionType, _ := parser.SectionType(diagnostic.Section)
+		warnings = append(warnings, fmt.Sprintf("[%s] %s", sectionType.SectionName(), diagnostic.Message))
+	}
+
+	text, err := formatter.FormatJSON(jsonDoc)
+	if err != nil {
+		return "", warnings, fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return text, warnings, nil
+}
+
+// buildFoundry renders a Foundry VTT dnd5e actor
+func buildFoundry(doc Document, _ Options) (string, []string, error) {
+	actor, warnings, err := export.BuildFoundryActor(doc.Result, doc.Spells, doc.Title)
+	if err != nil {
+		return "", warnings, fmt.Errorf("failed to build Foundry actor: %w", err)
+	}
+	text, err := export.FormatFoundry(actor)
+	if err != nil {
+		return "", warnings, fmt.Errorf("failed to encode Foundry actor: %w", err)
+	}
+	return text, warnings, nil
+}
+
+// buildFiveTools renders a 5etools homebrew file
+func buildFiveTools(doc Document, _ Options) (string, []string, error) {
+	homebrew, warnings, err := export.BuildFiveToolsHomebrew(doc.Result, doc.Spells, doc.Title)
+	if err != nil {
+		return "", warnings, fmt.Errorf("failed to build 5etools homebrew: %w", err)
+	}
+	text, err := export.FormatFiveTools(homebrew)
+	if err != nil {
+		return "", warnings, fmt.Errorf("failed to encode 5etools homebrew: %w", err)
+	}
+	return text, warnings, nil
+}
+
+// buildHomebrewery renders a Homebrewery monster block
+func buildHomebrewery(doc Document, _ Options) (string, []string, error) {
+	text, warnings, err := export.FormatHomebrewery(doc.Result, doc.Spells, doc.Title)
+	if err != nil {
+		return "", warnings, fmt.Errorf("failed to build Homebrewery block: %w", err)
+	}
+	return strings.TrimSuffix(text, "\n"), warnings, nil
+}
+
+// buildHTML renders the standalone stat-block preview page
+func buildHTML(doc Document, _ Options) (string, []string, error) {
+	page, warnings, err := formatter.FormatHTML(doc.Result, doc.Spells, doc.Title)
+	if err != nil {
+		return "", warnings, fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return strings.TrimSuffix(page, "\n"), warnings, nil
+}
+
+// vttRenderer adapts a VTT exporter to a single-file renderer
+func vttRenderer(exporter vtt.Exporter) Renderer {
+	return documentRenderer{
+		name:      exporter.Name(),
+		extension: exporter.Extension(),
+		build: func(doc Document, _ Options) (string, []string, error) {
+			text, warnings, err := exporter.Export(doc.Result, doc.Spells, doc.Title)
+			if err != nil {
+				return "", warnings, fmt.Errorf("failed to export %s: %w", exporter.Name(), err)
+			}
+			return text, warnings, nil
+		},
+	}
+}
+
+func init() {
+	// D&D Beyond shows markdown literally, so only its text is stripped
+	Register(textRenderer{format: DDB{}, strip: true})
+	Register(NewDocument(PlainText{}))
+	Register(NewDocument(Markdown{}))
+	Register(documentRenderer{name: "json", extension: ".json", build: buildJSON})
+	Register(documentRenderer{name: "foundry", extension: ".foundry.json", build: buildFoundry})
+	Register(roll20Renderer{})
+	Register(documentRenderer{name: "5etools", extension: ".5etools.json", build: buildFiveTools})
+	Register(documentRenderer{name: "homebrewery", extension: ".homebrewery.md", build: buildHomebrewery})
+	Register(documentRenderer{name: "html", extension: ".html", build: buildHTML})
+	Register(vttRenderer(vtt.ImprovedInitiative{}))
+	Register(vttRenderer(vtt.Generic{}))
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"character-tool/formatter"
+	"character-tool/parser"
+	"strings"
+)
+
+// DDB is the default text format: D&D Beyond's description syntax with
+// [rollable] and [spell] tags, one .txt file per section
+type DDB struct{}
+
+func (DDB) Name() string {
+	return Default
+}
+
+func (DDB) Extension() string {
+	return ".txt"
+}
+
+func (DDB) Ability(ability parser.Ability, spells map[string]bool) (string, []string, error) {
+	return formatter.FormatAbility(ability, spells)
+}
+
+func (DDB) Section(abilityType parser.AbilityType, abilities []string) string {
+	return strings.Join(abilities, "\n\n")
+}
+
+func (DDB) Document(title string, sections []formatter.FormattedSection) string {
+	return formatter.CombineSections(sections)
+}
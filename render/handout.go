@@ -0,0 +1,86 @@
+package render
+
+import (
+	"character-tool/export"
+	"character-tool/formatter"
+	"character-tool/parser"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PlainText renders a printable handout without D&D Beyond tags: rolls as
+// "+5" and "8 (1d8+3)", spells in _underscores_ and underlined headings
+type PlainText struct{}
+
+func (PlainText) Name() string {
+	return "text"
+}
+
+func (PlainText) Extension() string {
+	return ".handout.txt"
+}
+
+func (PlainText) Ability(ability parser.Ability, spells map[string]bool) (string, []string, error) {
+	text, warnings, err := export.HandoutText(ability.RollText, spells, "_")
+	if err != nil || ability.Name == "" {
+		return text, warnings, err
+	}
+	return ability.Title() + ". " + text, warnings, nil
+}
+
+func (PlainText) Section(abilityType parser.AbilityType, abilities []string) string {
+	return strings.Join(abilities, "\n\n")
+}
+
+func (PlainText) Document(title string, sections []formatter.FormattedSection) string {
+	var parts []string
+	if title != "" {
+		parts = append(parts, underline(title, "="))
+	}
+	for _, section := range sections {
+		parts = append(parts, underline(section.Type.SectionName(), "-")+"\n\n"+section.Text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// underline puts a line of marker characters under a heading
+func underline(heading, marker string) string {
+	return heading + "\n" + strings.Repeat(marker, utf8.RuneCountInString(heading))
+}
+
+// Markdown renders a printable handout as Markdown for pandoc: a YAML title
+// block, a heading per section, bold ability names and italic spells
+type Markdown struct{}
+
+func (Markdown) Name() string {
+	return "markdown"
+}
+
+func (Markdown) Extension() string {
+	return ".handout.md"
+}
+
+func (Markdown) Ability(ability parser.Ability, spells map[string]bool) (string, []string, error) {
+	text, warnings, err := export.HandoutText(ability.RollText, spells, "*")
+	if err != nil || ability.Name == "" {
+		return text, warnings, err
+	}
+	return "**" + ability.Title() + ".** " + text, warnings, nil
+}
+
+func (Markdown) Section(abilityType parser.AbilityType, abilities []string) string {
+	return strings.Join(abilities, "\n\n")
+}
+
+func (Markdown) Document(title string, sections []formatter.FormattedSection) string {
+	var parts []string
+	if title != "" {
+		// A double-quoted YAML string accepts Go's escapes for printable text
+		parts = append(parts, "---\ntitle: "+strconv.Quote(title)+"\n---")
+	}
+	for _, section := range sections {
+		parts = append(parts, "# "+section.Type.SectionName()+"\n\n"+section.Text)
+	}
+	return strings.Join(parts, "\n\n")
+}
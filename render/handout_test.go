@@ -0,0 +1,107 @@
+package render
+
+import (
+	"character-tool/batch"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlainText_Handout(t *testing.T) {
+	renderer, _ := Get("text")
+	output, err := renderer.Render(testDocument(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "fighter\n=======\n\n" +
+		"Traits\n------\n\nSpellcasting. Knows _Shield_ and _Made Up_.\n\n" +
+		"Actions\n-------\n\nLongsword. +5, 8 (1d8+3) slashing damage."
+	if output.Stdout != expected {
+		t.Errorf("Expected %q, got %q", expected, output.Stdout)
+	}
+	if len(output.Files) != 1 || output.Files[0].Name != "fighter.handout.txt" || output.Files[0].Content != expected+"\n" {
+		t.Errorf("Expected a single fighter.handout.txt, got %+v", output.Files)
+	}
+	if len(output.Warnings) != 1 {
+		t.Errorf("Expected the unknown spell warning, got %v", output.Warnings)
+	}
+}
+
+func TestMarkdown_Handout(t *testing.T) {
+	renderer, _ := Get("markdown")
+	doc := testDocument()
+	doc.Title = `the "red" fighter`
+
+	output, err := renderer.Render(doc, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "---\ntitle: \"the \\\"red\\\" fighter\"\n---\n\n" +
+		"# Traits\n\n**Spellcasting.** Knows *Shield* and *Made Up*.\n\n" +
+		"# Actions\n\n**Longsword.** +5, 8 (1d8+3) slashing damage."
+	if output.Stdout != expected {
+		t.Errorf("Expected %q, got %q", expected, output.Stdout)
+	}
+	if len(output.Files) != 1 || output.Files[0].Name != "fighter.handout.md" {
+		t.Errorf("Expected a single fighter.handout.md, got %+v", output.Files)
+	}
+}
+
+func TestHandout_PlainParagraphAndSection(t *testing.T) {
+	doc := testDocument()
+	doc.Result.Traits[0].Name = ""
+	doc.Result.Actions = nil
+
+	renderer, _ := Get("markdown")
+	output, err := renderer.Render(doc, Options{Section: &doc.Result.Traits[0].Type})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Handouts keep their title and heading even for a single section
+	if !strings.HasSuffix(output.Stdout, "# Traits\n\nKnows *Shield* and *Made Up*.") {
+		t.Errorf("Expected the unnamed paragraph under its heading, got %q", output.Stdout)
+	}
+}
+
+func TestExpandInputs_SkipsMarkdownOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{"handout", []string{"fighter.md", "fighter.handout.md"}, []string{"fighter.md"}},
+		{"homebrewery", []string{"fighter.md", "fighter.homebrewery.md"}, []string{"fighter.md"}},
+		{"built-in template", []string{"fighter.md", "fighter.bold.md"}, []string{"fighter.md"}},
+		{"any case", []string{"fighter.md", "fighter.Handout.MD"}, []string{"fighter.md"}},
+		{"similar names kept", []string{"handout.md", "fighter.notes.md"}, []string{"fighter.notes.md", "handout.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("## Traits\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			files, err := batch.ExpandInputs([]string{dir}, MarkdownSuffixes()...)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var expected []string
+			for _, name := range tt.expected {
+				expected = append(expected, filepath.Join(dir, name))
+			}
+			if !reflect.DeepEqual(files, expected) {
+				t.Errorf("Expected %v, got %v", expected, files)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package render
+
+import (
+	"character-tool/parser"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Default is the format used when none is requested
+const Default = "ddb"
+
+// Document is a parsed input ready to render
+type Document struct {
+	// Source is the input file path
+	Source string
+	// Stem is the input file name without its extension, used to name
+	// single-file outputs
+	Stem string
+	// Title is the character or creature name shown by formats that have one
+	Title  string
+	Result *parser.ParseResult
+	Spells map[string]bool
+}
+
+// Options controls how a renderer lays out its output
+type Options struct {
+	// Combined writes every section to one file instead of one file per section
+	Combined bool
+	// Section is set when the result was narrowed to a single section
+	Section *parser.AbilityType
+	// KeepMarkdown leaves inline markdown in D&D Beyond text, which
+	// otherwise has it stripped since D&D Beyond shows it literally
+	KeepMarkdown bool
+}
+
+// File is one output file, named relative to the output directory
+type File struct {
+	Name      string
+	Content   string
+	Abilities int
+}
+
+// Output is a rendered document
+type Output struct {
+	// Files are written to the output directory
+	Files []File
+	// Stdout is printed instead of writing files with --stdout
+	Stdout    string
+	Abilities int
+	Warnings  []string
+}
+
+// Renderer turns a parsed document into output files. Renderers decide their
+// own file layout; the caller only writes the files or prints Stdout.
+type Renderer interface {
+	// Name is the value passed to --format
+	Name() string
+	Render(doc Document, opts Options) (Output, error)
+}
+
+// suffixer is implemented by renderers whose files all end the same way,
+// such as "fighter.homebrewery.md"
+type suffixer interface {
+	Suffix() string
+}
+
+// MarkdownSuffixes returns the endings of the markdown files written by the
+// registered renderers, the built-in templates and any others given, such as
+// a template file. Batch runs skip these files, so output written next to
+// the inputs isn't read back in as a character.
+func MarkdownSuffixes(others ...Renderer) []string {
+	all := slices.Collect(maps.Values(renderers))
+	for _, fileName := range builtinTemplateFiles() {
+		name, extension := splitTemplateName(fileName)
+		all = append(all, templateRenderer{name: name, extension: extension})
+	}
+
+	var suffixes []string
+	for _, renderer := range append(all, others...) {
+		if r, ok := renderer.(suffixer); ok && strings.EqualFold(filepath.Ext(r.Suffix()), ".md") && !slices.Contains(suffixes, r.Suffix()) {
+			suffixes = append(suffixes, r.Suffix())
+		}
+	}
+	slices.Sort(suffixes)
+	return suffixes
+}
+
+// renderers holds the registered renderers by name
+var renderers = map[string]Renderer{}
+
+// Register makes a renderer available by name. It panics if the name is
+// already taken, since that is a programming error.
+func Register(renderer Renderer) {
+	if _, exists := renderers[renderer.Name()]; exists {
+		panic("render: renderer registered twice: " + renderer.Name())
+	}
+	renderers[renderer.Name()] = renderer
+}
+
+// Get returns the renderer registered under name
+func Get(name string) (Renderer, bool) {
+	renderer, ok := renderers[name]
+	return renderer, ok
+}
+
+// Names lists the registered renderers with the default first and the rest
+// in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		if name != Default {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	if _, ok := renderers[Default]; ok {
+		names = append([]string{Default}, names...)
+	}
+	return names
+}
+
+// abilityCount counts every ability in a parse result
+func abilityCount(result *parser.ParseResult) int {
+	count := 0
+	for _, section := range result.Sections() {
+		count += len(section.Abilities)
+	}
+	return count
+}
@@ -0,0 +1,241 @@
+package render
+
+import (
+	"character-tool/formatter"
+	"character-tool/parser"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testDocument is a small character with a trait, an attack and an unknown spell
+func testDocument() Document {
+	return Document{
+		Source: "notes/fighter.md",
+		Stem:   "fighter",
+		Title:  "fighter",
+		Result: &parser.ParseResult{
+			Traits: []parser.Ability{
+				{Name: "Spellcasting", Description: "Knows {{spell:Shield}} and {{spell:Made Up}}.", RollText: "Knows {{spell:Shield}} and {{spell:Made Up}}.", Type: parser.Trait},
+			},
+			Actions: []parser.Ability{
+				{Name: "Longsword", Description: "to hit: 1d20+5, damage: 1d8+3 slashing damage.", RollText: "to hit: 1d20+5, damage: 1d8+3 slashing damage.", Type: parser.Action},
+			},
+			BonusActions: []parser.Ability{},
+			Reactions:    []parser.Ability{},
+		},
+		Spells: map[string]bool{"shield": true},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	names := Names()
+	if names[0] != Default {
+		t.Errorf("Expected %q first, got %v", Default, names)
+	}
+	for _, name := range []string{"text", "markdown", "json", "foundry", "roll20", "5etools", "homebrewery", "html", "improved-initiative", "statblock"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("Expected %q to be registered, got %v", name, names)
+		}
+	}
+
+	for _, name := range names {
+		renderer, ok := Get(name)
+		if !ok || renderer.Name() != name {
+			t.Errorf("Expected Get(%q) to return the renderer, got %v", name, renderer)
+		}
+	}
+
+	if _, ok := Get("pdf"); ok {
+		t.Error("Expected unknown renderer to be missing")
+	}
+}
+
+func TestRegister_DuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic when registering a name twice")
+		}
+	}()
+	Register(NewText(DDB{}))
+}
+
+func TestDDB_FilePerSection(t *testing.T) {
+	output, err := NewText(DDB{}).Render(testDocument(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(output.Files) != 2 || output.Files[0].Name != "traits.txt" || output.Files[1].Name != "actions.txt" {
+		t.Fatalf("Expected traits.txt and actions.txt, got %+v", output.Files)
+	}
+	if !strings.HasPrefix(output.Files[1].Content, "Longsword. [rollable]+5;") {
+		t.Errorf("Expected D&D Beyond text, got %q", output.Files[1].Content)
+	}
+	if output.Abilities != 2 {
+		t.Errorf("Expected 2 abilities, got %d", output.Abilities)
+	}
+	if len(output.Warnings) != 1 || !strings.HasPrefix(output.Warnings[0], "[Traits] ") {
+		t.Errorf("Expected one Traits warning, got %v", output.Warnings)
+	}
+	if !strings.HasPrefix(output.Stdout, "## Traits\n\n") || !strings.Contains(output.Stdout, "\n\n## Actions\n\n") {
+		t.Errorf("Expected combined text on stdout, got %q", output.Stdout)
+	}
+}
+
+func TestDDB_Combined(t *testing.T) {
+	output, err := NewText(DDB{}).Render(testDocument(), Options{Combined: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(output.Files) != 1 || output.Files[0].Name != "fighter.txt" {
+		t.Fatalf("Expected fighter.txt, got %+v", output.Files)
+	}
+	if output.Files[0].Content != output.Stdout {
+		t.Errorf("Expected the combined file to match stdout, got %q", output.Files[0].Content)
+	}
+}
+
+func TestDDB_SingleSectionIsBare(t *testing.T) {
+	doc := testDocument()
+	doc.Result.Traits = nil
+	action := parser.Action
+
+	output, err := NewText(DDB{}).Render(doc, Options{Section: &action})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output.Stdout, "Longsword. ") {
+		t.Errorf("Expected the bare section text, got %q", output.Stdout)
+	}
+}
+
+func TestDDB_EmptyDocument(t *testing.T) {
+	output, err := NewText(DDB{}).Render(Document{Stem: "empty", Result: &parser.ParseResult{}}, Options{Combined: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Files) != 0 || output.Stdout != "" {
+		t.Errorf("Expected no output, got %+v", output)
+	}
+}
+
+// shoutFormat is a minimal text format used to check that formats only need
+// the hooks
+type shoutFormat struct{}
+
+func (shoutFormat) Name() string      { return "shout" }
+func (shoutFormat) Extension() string { return ".shout" }
+
+func (shoutFormat) Ability(ability parser.Ability, spells map[string]bool) (string, []string, error) {
+	return strings.ToUpper(ability.Name), nil, nil
+}
+
+func (shoutFormat) Section(abilityType parser.AbilityType, abilities []string) string {
+	return strings.Join(abilities, ",")
+}
+
+func (shoutFormat) Document(title string, sections []formatter.FormattedSection) string {
+	var parts []string
+	for _, section := range sections {
+		parts = append(parts, section.Text)
+	}
+	return title + ": " + strings.Join(parts, ";")
+}
+
+func TestNewText_CustomFormat(t *testing.T) {
+	output, err := NewText(shoutFormat{}).Render(testDocument(), Options{Combined: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Files) != 1 || output.Files[0].Name != "fighter.shout" {
+		t.Fatalf("Expected fighter.shout, got %+v", output.Files)
+	}
+	if output.Files[0].Content != "fighter: SPELLCASTING;LONGSWORD" {
+		t.Errorf("Expected the format's document, got %q", output.Files[0].Content)
+	}
+}
+
+func TestDocumentRenderers(t *testing.T) {
+	tests := []struct {
+		format string
+		files  []string
+		stdout string
+	}{
+		{"json", []string{"fighter.json"}, `"source": "notes/fighter.md"`},
+		{"foundry", []string{"fighter.foundry.json"}, `"type": "character"`},
+		{"roll20", []string{"fighter.roll20.txt", "fighter.roll20.json"}, "&{template:atkdmg}"},
+		{"5etools", []string{"fighter.5etools.json"}, `"monster"`},
+		{"homebrewery", []string{"fighter.homebrewery.md"}, "{{monster,frame"},
+		{"html", []string{"fighter.html"}, "<!DOCTYPE html>"},
+		{"statblock", []string{"fighter.statblock.json"}, `"name": "fighter"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			renderer, _ := Get(tt.format)
+			output, err := renderer.Render(testDocument(), Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, file := range output.Files {
+				names = append(names, file.Name)
+				if !strings.HasSuffix(file.Content, "\n") {
+					t.Errorf("Expected %s to end with a newline", file.Name)
+				}
+			}
+			if !slices.Equal(names, tt.files) {
+				t.Errorf("Expected files %v, got %v", tt.files, names)
+			}
+			if !strings.Contains(output.Stdout, tt.stdout) || strings.HasSuffix(output.Stdout, "\n") {
+				t.Errorf("Expected stdout containing %q without a trailing newline, got %q", tt.stdout, output.Stdout)
+			}
+		})
+	}
+}
+
+func TestJSON_DiagnosticsBecomeWarnings(t *testing.T) {
+	renderer, _ := Get("json")
+	output, err := renderer.Render(testDocument(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Warnings) != 1 || !strings.HasPrefix(output.Warnings[0], "[Traits] ") {
+		t.Errorf("Expected one Traits warning, got %v", output.Warnings)
+	}
+}
+
+func TestMarkdownStrippedOnlyForDDB(t *testing.T) {
+	doc := testDocument()
+	description := "Knows **Shield** from [the book](https://example.com)."
+	doc.Result.Traits[0].Description, doc.Result.Traits[0].RollText = description, description
+
+	tests := []struct {
+		format   string
+		keep     bool
+		contains string
+		excludes string
+	}{
+		{"ddb", false, "Knows Shield from the book.", "**"},
+		{"ddb", true, "Knows **Shield** from [the book](https://example.com).", ""},
+		{"markdown", false, "**Shield**", ""},
+		{"homebrewery", false, "**Shield**", ""},
+		{"json", false, `"description": "Knows **Shield** from [the book](https://example.com)."`, `"text": "Spellcasting. Knows **`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			renderer, _ := Get(tt.format)
+			output, err := renderer.Render(doc, Options{Combined: true, KeepMarkdown: tt.keep})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(output.Stdout, tt.contains) || (tt.excludes != "" && strings.Contains(output.Stdout, tt.excludes)) {
+				t.Errorf("Expected %q without %q, got %q", tt.contains, tt.excludes, output.Stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package render
+
+import (
+	"character-tool/converter"
+	"character-tool/export"
+	"character-tool/formatter"
+	"character-tool/parser"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// builtinTemplates holds the templates available by name to --template. Each
+// is named <name><ext>.tmpl, where ext is the output file extension.
+//
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// TemplateData is the document passed to output templates. Only sections
+// with abilities are included.
+type TemplateData struct {
+	// Title is the character name, taken from the input file name
+	Title string
+	// Source is the input file path
+	Source   string
+	Sections []TemplateSection
+}
+
+// TemplateSection is one section of the document
+type TemplateSection struct {
+	// Name is the section heading, such as "Bonus Actions"
+	Name string
+	// Slug is the file-name form, such as "bonus-actions"
+	Slug      string
+	Abilities []TemplateAbility
+}
+
+// TemplateAbility is one ability, with its description in every form
+type TemplateAbility struct {
+	// Name is empty for plain text paragraphs
+	Name string
+	// Usage is the usage split off the name, such as "Recharge 5–6"
+	Usage string
+	// Title is the name and usage with a D&D Beyond recharge roll
+	Title string
+	// PlainTitle is the name and usage without tags
+	PlainTitle string
+	// Type is the singular section name, such as "Bonus Action"
+	Type string
+	// Markdown is the description as written in the input
+	Markdown string
+	// Description is the D&D Beyond text, with [rollable] and [spell] tags
+	Description string
+	// Plain is the description without tags, as in the handout formats
+	Plain     string
+	Rollables []TemplateRollable
+	Spells    []TemplateSpell
+}
+
+// TemplateRollable is one dice roll in an ability
+type TemplateRollable struct {
+	// Notation is normalized dice notation, such as "1d8+3"
+	Notation string
+	// Type is "to hit", "damage", "healing" or "save"
+	Type string
+	// Display is the D&D Beyond display value, such as "+5" or "8(1d8+3)"
+	Display string
+	Average int
+}
+
+// TemplateSpell is a spell referenced by an ability
+type TemplateSpell struct {
+	Name string
+	// Known reports whether the spell is in the spell list
+	Known bool
+}
+
+// templateFuncs are the helper functions available to output templates
+var templateFuncs = template.FuncMap{
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+	"repeat":  strings.Repeat,
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"add":     func(a, b int) int { return a + b },
+}
+
+// templateRenderer renders the whole document through a text/template into
+// one file named <stem>.<name><ext>
+type templateRenderer struct {
+	name      string
+	extension string
+	template  *template.Template
+}
+
+// TemplateNames lists the built-in templates
+func TemplateNames() []string {
+	var names []string
+	for _, fileName := range builtinTemplateFiles() {
+		name, _ := splitTemplateName(fileName)
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LoadTemplate returns a renderer for a built-in template name or a template
+// file. The output extension comes from the file name: "table.md.tmpl"
+// writes .md files, and files without one write .txt.
+func LoadTemplate(nameOrPath string) (Renderer, error) {
+	for _, fileName := range builtinTemplateFiles() {
+		if name, _ := splitTemplateName(fileName); name == nameOrPath {
+			text, err := builtinTemplates.ReadFile(path.Join("templates", fileName))
+			if err != nil {
+				return nil, err
+			}
+			return NewTemplate(fileName, string(text))
+		}
+	}
+
+	text, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template (built-in templates are %s): %w", strings.Join(TemplateNames(), ", "), err)
+	}
+	return NewTemplate(filepath.Base(nameOrPath), string(text))
+}
+
+// NewTemplate parses a template for a file called fileName. The built-in
+// "ability" template is defined first, so templates can call or override it.
+func NewTemplate(fileName, text string) (Renderer, error) {
+	name, extension := splitTemplateName(fileName)
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(`{{define "ability"}}` + formatter.AbilityTemplate + `{{end}}`)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.Parse(text); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", fileName, err)
+	}
+
+	return templateRenderer{name: name, extension: extension, template: tmpl}, nil
+}
+
+// splitTemplateName splits "table.md.tmpl" into the name "table" and the
+// extension ".md"
+func splitTemplateName(fileName string) (string, string) {
+	name := strings.TrimSuffix(fileName, ".tmpl")
+	extension := filepath.Ext(name)
+	if extension == "" {
+		return name, ".txt"
+	}
+	return strings.TrimSuffix(name, extension), extension
+}
+
+// builtinTemplateFiles lists the embedded template files
+func builtinTemplateFiles() []string {
+	// The directory is embedded at build time, so reading it can't fail
+	entries, _ := builtinTemplates.ReadDir("templates")
+	var fileNames []string
+	for _, entry := range entries {
+		fileNames = append(fileNames, entry.Name())
+	}
+	return fileNames
+}
+
+func (r templateRenderer) Name() string {
+	return r.name
+}
+
+// Suffix returns the ending of the file name, such as ".bold.md"
+func (r templateRenderer) Suffix() string {
+	return "." + r.name + r.extension
+}
+
+// Render executes the template. Leading and trailing blank lines are
+// trimmed, so templates can put each action on its own line.
+func (r templateRenderer) Render(doc Document, opts Options) (Output, error) {
+	data, warnings, err := BuildTemplateData(doc)
+	output := Output{Warnings: warnings}
+	if err != nil {
+		return output, err
+	}
+
+	var b strings.Builder
+	if err := r.template.Execute(&b, data); err != nil {
+		return output, fmt.Errorf("failed to execute template %s: %w", r.name, err)
+	}
+	text := strings.Trim(b.String(), "\n")
+
+	output.Abilities = abilityCount(doc.Result)
+	output.Stdout = text
+	output.Files = []File{{Name: doc.Stem + "." + r.name + r.extension, Content: text + "\n", Abilities: output.Abilities}}
+	return output, nil
+}
+
+// BuildTemplateData converts a document into template data. Conversion
+// warnings are prefixed with their section name.
+func BuildTemplateData(doc Document) (*TemplateData, []string, error) {
+	data := &TemplateData{Title: doc.Title, Source: doc.Source, Sections: []TemplateSection{}}
+	var warnings []string
+
+	for _, section := range doc.Result.Sections() {
+		if len(section.Abilities) == 0 {
+			continue
+		}
+
+		templateSection := TemplateSection{
+			Name: section.Type.SectionName(),
+			Slug: section.Type.Slug(),
+		}
+
+		for _, ability := range section.Abilities {
+			converted, abilityWarnings, err := templateAbility(ability, doc.Spells)
+			for _, warning := range abilityWarnings {
+				warnings = append(warnings, fmt.Sprintf("[%s] %s", section.Type.SectionName(), warning))
+			}
+			if err != nil {
+				return nil, warnings, fmt.Errorf("failed to format %s: %w", section.Type.SectionName(), err)
+			}
+			templateSection.Abilities = append(templateSection.Abilities, converted)
+		}
+
+		data.Sections = append(data.Sections, templateSection)
+	}
+
+	return data, warnings, nil
+}
+
+// templateAbility converts one ability's description into every form
+func templateAbility(ability parser.Ability, spells map[string]bool) (TemplateAbility, []string, error) {
+	converted := TemplateAbility{
+		Name:       ability.Name,
+		Usage:      ability.Usage.String(),
+		Title:      converter.ConvertRecharge(ability.Title(), ability.Name),
+		PlainTitle: ability.Title(),
+		Type:       ability.Type.String(),
+		Markdown:   ability.Description,
+		Rollables:  []TemplateRollable{},
+		Spells:     []TemplateSpell{},
+	}
+
+	text, warnings := converter.ConvertSpellLinks(ability.RollText, spells)
+	text, err := converter.ConvertDiceRolls(text, ability.Name)
+	if err != nil {
+		return converted, warnings, err
+	}
+	converted.Description = converter.ConvertRecharge(text, ability.Name)
+
+	// Warnings were already reported by the conversion above
+	converted.Plain, _, err = export.HandoutText(ability.RollText, spells, "")
+	if err != nil {
+		return converted, warnings, err
+	}
+
+	for _, rollable := range converter.ExtractRollables(ability.RollText, ability.Name) {
+		converted.Rollables = append(converted.Rollables, TemplateRollable{
+			Notation: rollable.DiceNotation,
+			Type:     rollable.RollType,
+			Display:  converter.DisplayValue(rollable.DiceNotation),
+			Average:  converter.Average(rollable.DiceNotation),
+		})
+	}
+	for _, reference := range converter.ExtractSpellReferences(ability.RollText, spells) {
+		converted.Spells = append(converted.Spells, TemplateSpell{Name: reference.SpellName, Known: reference.IsValid})
+	}
+
+	return converted, warnings, nil
+}
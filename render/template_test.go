@@ -0,0 +1,202 @@
+package render
+
+import (
+	"character-tool/parser"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTemplateNames(t *testing.T) {
+	names := TemplateNames()
+	if !slices.Equal(names, []string{"bold", "colon", "ddb", "rules"}) {
+		t.Errorf("Expected the built-in templates, got %v", names)
+	}
+}
+
+func TestDDBTemplate_MatchesCombined(t *testing.T) {
+	renderer, err := LoadTemplate("ddb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := renderer.Render(testDocument(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	combined, err := NewText(DDB{}).Render(testDocument(), Options{Combined: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Stdout != combined.Stdout {
+		t.Errorf("Expected the --combined text %q, got %q", combined.Stdout, output.Stdout)
+	}
+	if len(output.Files) != 1 || output.Files[0].Name != "fighter.ddb.txt" {
+		t.Errorf("Expected fighter.ddb.txt, got %+v", output.Files)
+	}
+	if len(output.Warnings) != 1 || !strings.HasPrefix(output.Warnings[0], "[Traits] ") {
+		t.Errorf("Expected one Traits warning, got %v", output.Warnings)
+	}
+}
+
+func TestDDBTemplate_RechargeRoll(t *testing.T) {
+	doc := testDocument()
+	doc.Result.Actions = []parser.Ability{
+		{Name: "Fire Breath", Description: "damage: 6d6 fire damage.", RollText: "damage: 6d6 fire damage.", Type: parser.Action, Usage: parser.Usage{Recharge: 5}},
+	}
+
+	renderer, err := LoadTemplate("ddb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := renderer.Render(doc, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	combined, err := NewText(DDB{}).Render(doc, Options{Combined: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output.Stdout != combined.Stdout {
+		t.Errorf("Expected the --combined text %q, got %q", combined.Stdout, output.Stdout)
+	}
+	recharge := `Fire Breath ([rollable]Recharge 5–6;{"diceNotation":"1d6","rollType":"recharge","rollAction":"Fire Breath"}[/rollable]).`
+	if !strings.Contains(output.Stdout, recharge) {
+		t.Errorf("Expected a recharge roll in %q", output.Stdout)
+	}
+}
+
+func TestNewTemplate_DataAndFuncs(t *testing.T) {
+	text := `{{range .Sections}}{{upper .Name}} ({{.Slug}})
+{{range .Abilities}}{{.Name}}: {{.Plain}}
+{{range .Rollables}}{{.Type}}={{.Notation}} avg {{.Average}} shows {{.Display}}
+{{end}}{{range .Spells}}{{.Name}} known={{.Known}}
+{{end}}{{end}}{{end}}{{repeat "-" 3}} {{.Title}} from {{.Source}}`
+
+	renderer, err := NewTemplate("table.tmpl", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := renderer.Render(testDocument(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `TRAITS (traits)
+Spellcasting: Knows Shield and Made Up.
+Shield known=true
+Made Up known=false
+ACTIONS (actions)
+Longsword: +5, 8 (1d8+3) slashing damage.
+to hit=1d20+5 avg 16 shows +5
+damage=1d8+3 avg 8 shows 8(1d8+3)
+--- fighter from notes/fighter.md`
+	if output.Stdout != expected {
+		t.Errorf("Expected %q, got %q", expected, output.Stdout)
+	}
+	if output.Files[0].Name != "fighter.table.txt" || output.Files[0].Content != expected+"\n" {
+		t.Errorf("Expected fighter.table.txt ending in a newline, got %+v", output.Files[0])
+	}
+}
+
+func TestNewTemplate_OverrideAbility(t *testing.T) {
+	text := `{{define "ability"}}**{{.Name}}:** {{.Markdown}}{{end}}` +
+		`{{range .Sections}}{{range .Abilities}}{{template "ability" .}}{{"\n"}}{{end}}{{end}}`
+
+	renderer, err := NewTemplate("bold.md.tmpl", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := renderer.Render(testDocument(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "**Spellcasting:** Knows {{spell:Shield}} and {{spell:Made Up}}.\n**Longsword:** to hit: 1d20+5, damage: 1d8+3 slashing damage."
+	if output.Stdout != expected {
+		t.Errorf("Expected %q, got %q", expected, output.Stdout)
+	}
+	if output.Files[0].Name != "fighter.bold.md" {
+		t.Errorf("Expected fighter.bold.md, got %s", output.Files[0].Name)
+	}
+}
+
+func TestNewTemplate_Errors(t *testing.T) {
+	if _, err := NewTemplate("broken.tmpl", "{{range}}"); err == nil || !strings.Contains(err.Error(), "broken.tmpl") {
+		t.Errorf("Expected a parse error naming the file, got %v", err)
+	}
+
+	renderer, err := NewTemplate("missing.tmpl", "{{.Nope}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renderer.Render(testDocument(), Options{}); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestLoadTemplate_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.csv.tmpl")
+	if err := os.WriteFile(path, []byte(`{{range .Sections}}{{range .Abilities}}{{.Name}},{{end}}{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	renderer, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := renderer.Render(testDocument(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Stdout != "Spellcasting,Longsword," || output.Files[0].Name != "fighter.names.csv" {
+		t.Errorf("Expected names in fighter.names.csv, got %+v", output)
+	}
+
+	if _, err := LoadTemplate(filepath.Join(t.TempDir(), "nope.tmpl")); err == nil || !strings.Contains(err.Error(), "bold, colon, ddb, rules") {
+		t.Errorf("Expected an error listing the built-in templates, got %v", err)
+	}
+}
+
+func TestMarkdownSuffixes_Templates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table.md.tmpl")
+	if err := os.WriteFile(path, []byte(`{{.Title}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv, err := NewTemplate("names.csv.tmpl", `{{.Title}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{".bold.md", ".handout.md", ".homebrewery.md", ".table.md"}
+	if suffixes := MarkdownSuffixes(custom, csv); !slices.Equal(suffixes, expected) {
+		t.Errorf("Expected %v, got %v", expected, suffixes)
+	}
+}
+
+func TestSplitTemplateName(t *testing.T) {
+	tests := []struct {
+		fileName  string
+		name      string
+		extension string
+	}{
+		{"table.md.tmpl", "table", ".md"},
+		{"table.tmpl", "table", ".txt"},
+		{"table", "table", ".txt"},
+		{"table.html", "table", ".html"},
+	}
+
+	for _, tt := range tests {
+		name, extension := splitTemplateName(tt.fileName)
+		if name != tt.name || extension != tt.extension {
+			t.Errorf("splitTemplateName(%q) = %q, %q; expected %q, %q", tt.fileName, name, extension, tt.name, tt.extension)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package render
+
+import (
+	"character-tool/formatter"
+	"character-tool/parser"
+	"fmt"
+)
+
+// TextFormat renders a text format one piece at a time. NewText wraps it in
+// a Renderer that handles the file layout.
+type TextFormat interface {
+	// Name is the value passed to --format
+	Name() string
+	// Extension is appended to section slugs and the input stem to name files
+	Extension() string
+	// Ability renders a single ability, returning conversion warnings
+	Ability(ability parser.Ability, spells map[string]bool) (string, []string, error)
+	// Section joins the rendered abilities of one section
+	Section(abilityType parser.AbilityType, abilities []string) string
+	// Document joins rendered sections into a single document titled title
+	Document(title string, sections []formatter.FormattedSection) string
+}
+
+// textRenderer lays out a text format as one file per section, or a single
+// combined file with --combined
+type textRenderer struct {
+	format TextFormat
+	// single always writes the combined file, for formats read as a whole
+	single bool
+	// strip removes inline markdown first, unless Options.KeepMarkdown is set
+	strip bool
+}
+
+// NewText returns a Renderer for a text format
+func NewText(format TextFormat) Renderer {
+	return textRenderer{format: format}
+}
+
+// NewDocument returns a Renderer for a text format that always writes a
+// single file named after the input, such as a printable handout
+func NewDocument(format TextFormat) Renderer {
+	return textRenderer{format: format, single: true}
+}
+
+func (r textRenderer) Name() string {
+	return r.format.Name()
+}
+
+// Suffix returns the ending of the single file written for each input, such
+// as ".handout.md". Per-section files are named after the section, so they
+// have none.
+func (r textRenderer) Suffix() string {
+	if !r.single {
+		return ""
+	}
+	return r.format.Extension()
+}
+
+func (r textRenderer) Render(doc Document, opts Options) (Output, error) {
+	var output Output
+	if r.strip && !opts.KeepMarkdown {
+		doc.Result = formatter.StripMarkdown(doc.Result)
+	}
+
+	sections, err := r.sections(doc)
+	if err != nil {
+		return output, err
+	}
+
+	for _, section := range sections {
+		output.Abilities += section.Abilities
+		for _, warning := range section.Warnings {
+			output.Warnings = append(output.Warnings, fmt.Sprintf("[%s] %s", section.Type.SectionName(), warning))
+		}
+	}
+	if len(sections) == 0 {
+		return output, nil
+	}
+
+	document := r.format.Document(doc.Title, sections)
+
+	// A single requested section is printed bare, ready to paste
+	if opts.Section != nil && len(sections) == 1 && !r.single {
+		output.Stdout = sections[0].Text
+	} else {
+		output.Stdout = document
+	}
+
+	if r.single {
+		output.Files = []File{{Name: doc.Stem + r.format.Extension(), Content: document + "\n", Abilities: output.Abilities}}
+		return output, nil
+	}
+	if opts.Combined {
+		output.Files = []File{{Name: doc.Stem + r.format.Extension(), Content: document, Abilities: output.Abilities}}
+		return output, nil
+	}
+
+	for _, section := range sections {
+		output.Files = append(output.Files, File{
+			Name:      section.Type.Slug() + r.format.Extension(),
+			Content:   section.Text,
+			Abilities: section.Abilities,
+		})
+	}
+
+	return output, nil
+}
+
+// sections renders every non-empty section in output order
+func (r textRenderer) sections(doc Document) ([]formatter.FormattedSection, error) {
+	var sections []formatter.FormattedSection
+
+	for _, section := range doc.Result.Sections() {
+		if len(section.Abilities) == 0 {
+			continue
+		}
+
+		var texts, warnings []string
+		for _, ability := range section.Abilities {
+			text, abilityWarnings, err := r.format.Ability(ability, doc.Spells)
+			warnings = append(warnings, abilityWarnings...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to format %s: %w", section.Type.SectionName(), err)
+			}
+			texts = append(texts, text)
+		}
+
+		sections = append(sections, formatter.FormattedSection{
+			Type:      section.Type,
+			Text:      r.format.Section(section.Type, texts),
+			Abilities: len(section.Abilities),
+			Warnings:  warnings,
+		})
+	}
+
+	return sections, nil
+}
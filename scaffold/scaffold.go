@@ -0,0 +1,251 @@
+package scaffold
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// builtin holds the starter templates, one directory per kind
+//
+//go:embed templates
+var builtin embed.FS
+
+// Kind is a group of starter templates
+type Kind string
+
+const (
+	// Class templates are player characters, scaled by level
+	Class Kind = "class"
+	// Monster templates are creature archetypes, scaled by challenge rating
+	Monster Kind = "monster"
+)
+
+// Options selects a starter template and the character it is filled in for
+type Options struct {
+	Kind Kind
+	// Template is the class or archetype, e.g. "wizard" or "brute"
+	Template string
+	// Name defaults to the template name in title case
+	Name string
+	// Level is the character level (1-20) or the monster's challenge rating (0-30)
+	Level int
+	// Dir overrides the built-in templates: <Dir>/<kind>/<template>.md is
+	// used when it exists, and adds new templates
+	Dir string
+}
+
+// Data is passed to starter templates. Numbers follow the SRD progression
+// for the level or challenge rating.
+type Data struct {
+	Name string
+	// Level is the character level; 0 for monsters
+	Level int
+	// CR is the monster's challenge rating; 0 for characters
+	CR               int
+	ProficiencyBonus int
+	// Modifier is the main ability modifier
+	Modifier int
+	// AttackBonus is Modifier plus ProficiencyBonus
+	AttackBonus int
+	// SaveDC is the DC of saving throws against the character's abilities
+	SaveDC int
+	// CantripDice is the number of damage dice of a cantrip at this level
+	CantripDice int
+	// DamageDice is the number of damage dice of a monster's attacks
+	DamageDice int
+}
+
+// slugRegex matches the runs of characters replaced in file names
+var slugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// templateFuncs are the helper functions available to starter templates
+var templateFuncs = template.FuncMap{
+	"add":   func(a, b int) int { return a + b },
+	"div":   func(a, b int) int { return a / b },
+	"lower": strings.ToLower,
+	// yaml quotes a frontmatter value; Go's escapes are valid in YAML
+	// double-quoted strings
+	"yaml": strconv.Quote,
+}
+
+// Templates lists the templates of a kind, built-in and from dir
+func Templates(kind Kind, dir string) []string {
+	var names []string
+	add := func(fileNames []string) {
+		for _, fileName := range fileNames {
+			if name, ok := strings.CutSuffix(fileName, ".md"); ok && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	entries, _ := fs.ReadDir(builtin, path.Join("templates", string(kind)))
+	add(entryNames(entries))
+	if dir != "" {
+		entries, _ := os.ReadDir(filepath.Join(dir, string(kind)))
+		add(entryNames(entries))
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// Generate fills in a starter template, returning the character markdown
+func Generate(opts Options) (string, error) {
+	data, err := newData(opts)
+	if err != nil {
+		return "", err
+	}
+
+	text, err := readTemplate(opts.Kind, opts.Template, opts.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(opts.Template).Delims("[[", "]]").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template %s: %w", opts.Kind, opts.Template, err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to fill in %s template %s: %w", opts.Kind, opts.Template, err)
+	}
+	return b.String(), nil
+}
+
+// FileName names the markdown file for a character: its name, or the
+// template name, as a lower-case slug
+func FileName(opts Options) string {
+	name := opts.Name
+	if name == "" {
+		name = opts.Template
+	}
+	return strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(name), "-"), "-") + ".md"
+}
+
+// readTemplate returns the override from dir if there is one, otherwise the
+// built-in template
+func readTemplate(kind Kind, name, dir string) (string, error) {
+	fileName := name + ".md"
+
+	if dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, string(kind), fileName))
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	content, err := builtin.ReadFile(path.Join("templates", string(kind), fileName))
+	if err != nil {
+		return "", fmt.Errorf("unknown %s %q (must be one of %s)", kind, name, strings.Join(Templates(kind, dir), ", "))
+	}
+	return string(content), nil
+}
+
+// newData works out the template numbers for a level or challenge rating
+func newData(opts Options) (Data, error) {
+	if opts.Template == "" {
+		return Data{}, fmt.Errorf("no %s given", opts.Kind)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = strings.ToUpper(opts.Template[:1]) + opts.Template[1:]
+	}
+
+	switch opts.Kind {
+	case Class:
+		if opts.Level < 1 || opts.Level > 20 {
+			return Data{}, fmt.Errorf("level must be between 1 and 20, got %d", opts.Level)
+		}
+		return characterData(name, opts.Level), nil
+	case Monster:
+		if opts.Level < 0 || opts.Level > 30 {
+			return Data{}, fmt.Errorf("challenge rating must be between 0 and 30, got %d", opts.Level)
+		}
+		return monsterData(name, opts.Level), nil
+	default:
+		return Data{}, fmt.Errorf("unknown template kind %q", opts.Kind)
+	}
+}
+
+// characterData assumes a main ability score of 16, raised by the level 4
+// and 8 ability score improvements
+func characterData(name string, level int) Data {
+	modifier := 3
+	if level >= 4 {
+		modifier++
+	}
+	if level >= 8 {
+		modifier++
+	}
+
+	cantripDice := 1
+	for _, step := range []int{5, 11, 17} {
+		if level >= step {
+			cantripDice++
+		}
+	}
+
+	proficiency := 2 + (level-1)/4
+	return Data{
+		Name:             name,
+		Level:            level,
+		ProficiencyBonus: proficiency,
+		Modifier:         modifier,
+		AttackBonus:      modifier + proficiency,
+		SaveDC:           8 + modifier + proficiency,
+		CantripDice:      cantripDice,
+	}
+}
+
+// monsterAttackBonus is the attack bonus by challenge rating from the
+// monster creation guidelines, indexed by the first CR it applies to
+var monsterAttackBonus = []struct{ cr, bonus int }{
+	{0, 3}, {3, 4}, {4, 5}, {5, 6}, {8, 7}, {11, 8}, {16, 9}, {17, 10}, {21, 11}, {24, 12}, {27, 13}, {30, 14},
+}
+
+// monsterData follows the monster creation guidelines for attack bonus,
+// save DC and proficiency; damage dice grow every two challenge ratings
+func monsterData(name string, cr int) Data {
+	attackBonus := 0
+	for _, row := range monsterAttackBonus {
+		if cr >= row.cr {
+			attackBonus = row.bonus
+		}
+	}
+
+	proficiency := 2 + max(cr-1, 0)/4
+	return Data{
+		Name:             name,
+		CR:               cr,
+		ProficiencyBonus: proficiency,
+		Modifier:         attackBonus - proficiency,
+		AttackBonus:      attackBonus,
+		SaveDC:           9 + attackBonus,
+		DamageDice:       1 + cr/2,
+	}
+}
+
+// entryNames returns the names of directory entries
+func entryNames(entries []fs.DirEntry) []string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
@@ -0,0 +1,190 @@
+package scaffold
+
+import (
+	"character-tool/converter"
+	"character-tool/formatter"
+	"character-tool/parser"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	if classes := Templates(Class, ""); !slices.Equal(classes, []string{"barbarian", "cleric", "fighter", "rogue", "wizard"}) {
+		t.Errorf("Expected the built-in classes, got %v", classes)
+	}
+	if monsters := Templates(Monster, ""); !slices.Equal(monsters, []string{"brute", "caster", "skirmisher"}) {
+		t.Errorf("Expected the built-in monsters, got %v", monsters)
+	}
+}
+
+// TestGenerate_AllTemplatesConvert checks every built-in template at low and
+// high levels produces markdown the formatter converts without warnings
+func TestGenerate_AllTemplatesConvert(t *testing.T) {
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kind := range []Kind{Class, Monster} {
+		for _, name := range Templates(kind, "") {
+			for _, level := range []int{1, 5, 20} {
+				t.Run(fmt.Sprintf("%s/%s/%d", kind, name, level), func(t *testing.T) {
+					markdown, err := Generate(Options{Kind: kind, Template: name, Level: level})
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if !strings.HasPrefix(markdown, "---\nname: \"") {
+						t.Errorf("Expected frontmatter, got %q", markdown[:20])
+					}
+					for _, heading := range []string{"## Traits", "## Actions", "## Bonus Actions", "## Reactions"} {
+						if !strings.Contains(markdown, "\n"+heading+"\n") {
+							t.Errorf("Expected %q heading", heading)
+						}
+					}
+
+					parsed, err := parser.ParseMarkdown(markdown)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(parsed.Traits) == 0 || len(parsed.Actions) == 0 {
+						t.Errorf("Expected traits and actions, got %+v", parsed)
+					}
+
+					sections, err := formatter.FormatSections(parsed, spells)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, section := range sections {
+						if len(section.Warnings) != 0 {
+							t.Errorf("Expected no warnings in %s, got %v", section.Type.SectionName(), section.Warnings)
+						}
+						if strings.Contains(section.Text, "to hit:") || strings.Contains(section.Text, "damage:") || strings.Contains(section.Text, "[[") {
+							t.Errorf("Expected every roll to convert in %s, got %q", section.Type.SectionName(), section.Text)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestGenerate_ScalesWithLevel(t *testing.T) {
+	low, err := Generate(Options{Kind: Class, Template: "wizard", Level: 1, Name: "Elara"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	high, err := Generate(Options{Kind: Class, Template: "wizard", Level: 5, Name: "Elara"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"name: \"Elara\"\n", "level: 1\n", "to hit: 1d20+5,", "damage: 1d10 fire"} {
+		if !strings.Contains(low, expected) {
+			t.Errorf("Expected level 1 to contain %q", expected)
+		}
+	}
+	for _, expected := range []string{"level: 5\n", "to hit: 1d20+7,", "damage: 2d10 fire", "{{spell:Fireball}}", "**Misty Step.**"} {
+		if !strings.Contains(high, expected) {
+			t.Errorf("Expected level 5 to contain %q", expected)
+		}
+	}
+	if strings.Contains(low, "Fireball") || strings.Contains(low, "**Misty Step.**") {
+		t.Error("Expected level 1 to leave out higher level spells")
+	}
+}
+
+func TestData(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     Data
+		expected Data
+	}{
+		{"level 1", characterData("a", 1), Data{Name: "a", Level: 1, ProficiencyBonus: 2, Modifier: 3, AttackBonus: 5, SaveDC: 13, CantripDice: 1}},
+		{"level 11", characterData("a", 11), Data{Name: "a", Level: 11, ProficiencyBonus: 4, Modifier: 5, AttackBonus: 9, SaveDC: 17, CantripDice: 3}},
+		{"level 20", characterData("a", 20), Data{Name: "a", Level: 20, ProficiencyBonus: 6, Modifier: 5, AttackBonus: 11, SaveDC: 19, CantripDice: 4}},
+		{"CR 0", monsterData("a", 0), Data{Name: "a", CR: 0, ProficiencyBonus: 2, Modifier: 1, AttackBonus: 3, SaveDC: 12, DamageDice: 1}},
+		{"CR 5", monsterData("a", 5), Data{Name: "a", CR: 5, ProficiencyBonus: 3, Modifier: 3, AttackBonus: 6, SaveDC: 15, DamageDice: 3}},
+		{"CR 30", monsterData("a", 30), Data{Name: "a", CR: 30, ProficiencyBonus: 9, Modifier: 5, AttackBonus: 14, SaveDC: 23, DamageDice: 16}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.data != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, tt.data)
+			}
+		})
+	}
+}
+
+func TestGenerate_OverrideDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "class"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, "class", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("wizard.md", "## Traits\n\n**Custom.** Level [[.Level]] wizard.\n")
+	write("artificer.md", "## Actions\n\n**Tinker.** [[.Name]] builds things.\n")
+
+	markdown, err := Generate(Options{Kind: Class, Template: "wizard", Level: 3, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if markdown != "## Traits\n\n**Custom.** Level 3 wizard.\n" {
+		t.Errorf("Expected the override, got %q", markdown)
+	}
+
+	if classes := Templates(Class, dir); !slices.Contains(classes, "artificer") || !slices.Contains(classes, "fighter") {
+		t.Errorf("Expected built-in and added classes, got %v", classes)
+	}
+	markdown, err = Generate(Options{Kind: Class, Template: "artificer", Level: 1, Dir: dir})
+	if err != nil || !strings.Contains(markdown, "Artificer builds") {
+		t.Errorf("Expected the added template, got %q, %v", markdown, err)
+	}
+
+	// Templates missing from the directory fall back to the built-in ones
+	if _, err := Generate(Options{Kind: Class, Template: "rogue", Level: 1, Dir: dir}); err != nil {
+		t.Errorf("Expected the built-in rogue, got %v", err)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"unknown class", Options{Kind: Class, Template: "bard", Level: 1}, `unknown class "bard" (must be one of barbarian,`},
+		{"unknown monster", Options{Kind: Monster, Template: "dragon", Level: 1}, `unknown monster "dragon"`},
+		{"level too low", Options{Kind: Class, Template: "wizard", Level: 0}, "level must be between 1 and 20"},
+		{"level too high", Options{Kind: Class, Template: "wizard", Level: 21}, "level must be between 1 and 20"},
+		{"negative CR", Options{Kind: Monster, Template: "brute", Level: -1}, "challenge rating must be between 0 and 30"},
+		{"no template", Options{Kind: Class, Level: 1}, "no class given"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	if name := FileName(Options{Template: "wizard"}); name != "wizard.md" {
+		t.Errorf("Expected wizard.md, got %s", name)
+	}
+	if name := FileName(Options{Template: "wizard", Name: "Elara the Wise!"}); name != "elara-the-wise.md" {
+		t.Errorf("Expected elara-the-wise.md, got %s", name)
+	}
+}
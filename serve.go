@@ -0,0 +1,106 @@
+package main
+
+import (
+	"character-tool/converter"
+	"character-tool/parser"
+	"character-tool/server"
+	"character-tool/watcher"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+)
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [directory]",
+	Short: "Serve live stat-block previews of a directory of characters",
+	Long: `Starts a local web server that renders every markdown file in the directory
+(default: current directory) as a stat-block preview. Clicking a rollable rolls
+it with the built-in dice roller, and pages reload automatically when their
+markdown changes. Everything runs locally, so it works offline at the table.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		cmd.SilenceUsage = true
+		return runServe(dir, serveAddr, watcher.Options{
+			Debounce:     debounce,
+			Poll:         pollMode,
+			PollInterval: pollInterval,
+		})
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().DurationVar(&debounce, "debounce", watcher.DefaultDebounce, "wait this long after the last save before reloading")
+	serveCmd.Flags().BoolVar(&pollMode, "poll", false, "poll for changes instead of using filesystem notifications")
+	serveCmd.Flags().DurationVar(&pollInterval, "poll-interval", watcher.DefaultPollInterval, "how often to scan for changes in poll mode")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(dir, addr string, opts watcher.Options) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		return fmt.Errorf("failed to load spell list: %w", err)
+	}
+
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	srv := server.New(dir, spells, rng, parser.Options{Ruleset: ruleset, InferRolls: inferRolls})
+
+	w, err := watcher.New([]string{dir}, opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	httpServer := &http.Server{Addr: addr, Handler: srv}
+	errs := make(chan error, 2)
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+	go func() {
+		errs <- w.Run(ctx, func(changed []string) {
+			for _, path := range changed {
+				fmt.Printf("%s ↻ %s\n", timestamp(), path)
+			}
+			srv.Notify(changed)
+		})
+	}()
+
+	fmt.Printf("Serving %s at http://%s (watching with %s). Press Ctrl+C to stop.\n", dir, addr, w.Mode())
+
+	select {
+	case <-ctx.Done():
+	case err := <-errs:
+		if err != nil {
+			httpServer.Close()
+			return err
+		}
+	}
+
+	// Open event streams never finish on their own, so close rather than shut down
+	return httpServer.Close()
+}
@@ -0,0 +1,314 @@
+package server
+
+import (
+	"character-tool/batch"
+	"character-tool/converter"
+	"character-tool/formatter"
+	"character-tool/parser"
+	"character-tool/render"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Server serves stat-block previews of every character in a directory,
+// rolls dice for clicked rollables and pushes live reloads over SSE
+type Server struct {
+	dir    string
+	spells map[string]bool
+	// parse is how character pages are parsed, from --ruleset and
+	// --infer-rolls
+	parse parser.Options
+	mux   *http.ServeMux
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+
+	clientsMu sync.Mutex
+	clients   map[chan string]struct{}
+}
+
+// New creates a server for the markdown files under dir, parsing them with
+// the given options
+func New(dir string, spells map[string]bool, rng *rand.Rand, parse parser.Options) *Server {
+	s := &Server{
+		dir:     dir,
+		spells:  spells,
+		parse:   parse,
+		rng:     rng,
+		clients: make(map[chan string]struct{}),
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.HandleFunc("GET /character/{path...}", s.handleCharacter)
+	s.mux.HandleFunc("POST /roll", s.handleRoll)
+	s.mux.HandleFunc("GET /events", s.handleEvents)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Notify tells connected browsers that the given files changed so pages
+// showing them reload
+func (s *Server) Notify(changed []string) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	for _, path := range changed {
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			continue
+		}
+		for client := range s.clients {
+			// Drop the event for clients that aren't keeping up
+			select {
+			case client <- filepath.ToSlash(rel):
+			default:
+			}
+		}
+	}
+}
+
+// characters lists markdown files under the directory, relative to it
+func (s *Server) characters() ([]string, error) {
+	files, err := batch.ExpandInputs([]string{s.dir}, render.MarkdownSuffixes()...)
+	if err != nil {
+		return nil, err
+	}
+
+	characters := make([]string, 0, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(s.dir, file)
+		if err != nil {
+			continue
+		}
+		characters = append(characters, filepath.ToSlash(rel))
+	}
+	return characters, nil
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	characters, err := s.characters()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	indexTemplate.Execute(w, struct {
+		Characters []string
+		Script     template.JS
+	}{characters, liveReloadScript})
+}
+
+func (s *Server) handleCharacter(w http.ResponseWriter, r *http.Request) {
+	rel := r.PathValue("path")
+	path, ok := s.resolve(rel)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	parsed, err := parser.ParseMarkdownWith(string(content), s.parse)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse markdown: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	sections, _, err := formatter.BuildHTMLSections(parsed, s.spells)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to format: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	page, err := formatter.RenderHTMLPage(formatter.HTMLPage{
+		Title:    formatter.DisplayTitle(path),
+		Sections: sections,
+		Script:   characterScript,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, page)
+}
+
+// resolve maps a URL path to a markdown file inside the directory,
+// rejecting anything that would escape it
+func (s *Server) resolve(rel string) (string, bool) {
+	if rel == "" || !batch.IsMarkdown(rel) {
+		return "", false
+	}
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(s.dir, clean), true
+}
+
+// rollResponse is returned by POST /roll
+type rollResponse struct {
+	converter.RollResult
+	RollType   string `json:"rollType"`
+	RollAction string `json:"rollAction"`
+}
+
+// maxRollRequest is the largest POST /roll body accepted, in bytes
+const maxRollRequest = 4096
+
+func (s *Server) handleRoll(w http.ResponseWriter, r *http.Request) {
+	// Requiring JSON keeps other web pages from sending rolls with a plain
+	// form post, which browsers allow without asking the server first
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "roll requests must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req converter.RollableData
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRollRequest)).Decode(&req); err != nil {
+		http.Error(w, "invalid roll request", http.StatusBadRequest)
+		return
+	}
+
+	s.rngMu.Lock()
+	result, err := converter.Roll(req.DiceNotation, s.rng)
+	s.rngMu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rollResponse{
+		RollResult: result,
+		RollType:   req.RollType,
+		RollAction: req.RollAction,
+	})
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	client := make(chan string, 16)
+	s.clientsMu.Lock()
+	s.clients[client] = struct{}{}
+	s.clientsMu.Unlock()
+
+	defer func() {
+		s.clientsMu.Lock()
+		delete(s.clients, client)
+		s.clientsMu.Unlock()
+	}()
+
+	// Send a comment immediately so the browser knows the stream is open
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case path := <-client:
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", path)
+			flusher.Flush()
+		}
+	}
+}
+
+// liveReloadScript reloads the page when any character changes (index) or
+// when the character being viewed changes
+const liveReloadScript template.JS = `
+(function () {
+  var current = decodeURIComponent(location.pathname.replace(/^\/character\//, ""));
+  var onIndex = location.pathname === "/";
+  var events = new EventSource("/events");
+  events.addEventListener("change", function (e) {
+    if (onIndex || e.data === current) {
+      location.reload();
+    }
+  });
+})();
+`
+
+// characterScript adds click-to-roll to rollable buttons on top of live reload
+const characterScript = liveReloadScript + `
+(function () {
+  var log = document.getElementById("roll-log");
+  document.querySelectorAll("button.rollable").forEach(function (button) {
+    button.addEventListener("click", function () {
+      fetch("/roll", {
+        method: "POST",
+        headers: {"Content-Type": "application/json"},
+        body: JSON.stringify({
+          diceNotation: button.dataset.notation,
+          rollType: button.dataset.rollType,
+          rollAction: button.dataset.rollAction
+        })
+      })
+        .then(function (response) { return response.json(); })
+        .then(function (roll) {
+          var entry = document.createElement("div");
+          var label = (roll.rollAction ? roll.rollAction + " " : "") + roll.rollType;
+          var detail = "[" + roll.rolls.join(", ") + "]" + (roll.modifier ? (roll.modifier > 0 ? " +" : " ") + roll.modifier : "");
+          entry.textContent = label + " (" + roll.notation + "): " + roll.total + " " + detail;
+          log.insertBefore(entry, log.firstChild);
+        });
+    });
+  });
+})();
+`
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Characters</title>
+<style>
+body { background: #f4f1ea; margin: 2rem; font-family: "Helvetica Neue", Arial, sans-serif; }
+h1 { color: #7a200d; font-family: Georgia, serif; font-variant: small-caps; }
+a { color: #7a200d; }
+</style>
+</head>
+<body>
+<h1>Characters</h1>
+<ul>
+{{- range .Characters}}
+<li><a href="/character/{{.}}">{{.}}</a></li>
+{{- else}}
+<li>No markdown files found</li>
+{{- end}}
+</ul>
+<script>
+{{.Script}}
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bufio"
+	"character-tool/parser"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer creates a server over a temporary directory of characters
+func newTestServer(t *testing.T) (*Server, *httptest.Server, string) {
+	t.Helper()
+	return newTestServerWith(t, parser.Options{})
+}
+
+// newTestServerWith creates a test server that parses with opts
+func newTestServerWith(t *testing.T, opts parser.Options) (*Server, *httptest.Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"fighter.md":         "## Actions\n\n**Longsword.** Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing.\n",
+		"monsters/goblin.md": "## Traits\n\n**Nimble Escape.** Disengage or Hide as a bonus action.\n",
+		"monsters/wolf.md":   "## Actions\n\n**Bite.** +4 to hit. Hit: 7 (2d4 + 2) piercing damage.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := New(dir, map[string]bool{}, rand.New(rand.NewPCG(1, 2)), opts)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return s, ts, dir
+}
+
+// get fetches a URL and returns the status code and body
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestServer_Index(t *testing.T) {
+	_, ts, _ := newTestServer(t)
+
+	status, body := get(t, ts.URL+"/")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	for _, link := range []string{`href="/character/fighter.md"`, `href="/character/monsters/goblin.md"`} {
+		if !strings.Contains(body, link) {
+			t.Errorf("Expected index to contain %s", link)
+		}
+	}
+	if !strings.Contains(body, "EventSource") {
+		t.Error("Expected live reload script on index")
+	}
+}
+
+func TestServer_Character(t *testing.T) {
+	_, ts, _ := newTestServer(t)
+
+	status, body := get(t, ts.URL+"/character/fighter.md")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+
+	expected := []string{
+		"<title>fighter</title>",
+		`data-notation="1d20+5"`,
+		`<div id="roll-log"></div>`,
+		`fetch("/roll"`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(body, exp) {
+			t.Errorf("Expected page to contain %q", exp)
+		}
+	}
+}
+
+func TestServer_CharacterParseOptions(t *testing.T) {
+	tests := []struct {
+		opts     parser.Options
+		expected bool
+	}{
+		{parser.Options{}, false},
+		{parser.Options{InferRolls: true}, true},
+	}
+
+	for _, tt := range tests {
+		_, ts, _ := newTestServerWith(t, tt.opts)
+		_, body := get(t, ts.URL+"/character/monsters/wolf.md")
+		if strings.Contains(body, `data-notation="1d20+4"`) != tt.expected {
+			t.Errorf("Expected the inferred attack roll with %+v: %v", tt.opts, tt.expected)
+		}
+	}
+}
+
+func TestServer_CharacterNotFound(t *testing.T) {
+	_, ts, _ := newTestServer(t)
+
+	paths := []string{
+		"/character/missing.md",
+		"/character/..%2Fsecret.md",
+		"/character/notes.txt",
+	}
+	for _, path := range paths {
+		if status, _ := get(t, ts.URL+path); status != http.StatusNotFound {
+			t.Errorf("Expected 404 for %s, got %d", path, status)
+		}
+	}
+}
+
+func TestServer_Roll(t *testing.T) {
+	_, ts, _ := newTestServer(t)
+
+	body := `{"diceNotation":"2d6+3","rollType":"damage","rollAction":"Greatsword"}`
+	resp, err := http.Post(ts.URL+"/roll", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	var roll struct {
+		Notation   string `json:"notation"`
+		Rolls      []int  `json:"rolls"`
+		Modifier   int    `json:"modifier"`
+		Total      int    `json:"total"`
+		RollType   string `json:"rollType"`
+		RollAction string `json:"rollAction"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&roll); err != nil {
+		t.Fatal(err)
+	}
+
+	if roll.Notation != "2d6+3" || len(roll.Rolls) != 2 || roll.Modifier != 3 {
+		t.Errorf("Unexpected roll: %+v", roll)
+	}
+	if roll.Total < 5 || roll.Total > 15 {
+		t.Errorf("Total %d out of range", roll.Total)
+	}
+	if roll.RollType != "damage" || roll.RollAction != "Greatsword" {
+		t.Errorf("Expected roll metadata echoed back, got %+v", roll)
+	}
+}
+
+func TestServer_RollInvalid(t *testing.T) {
+	_, ts, _ := newTestServer(t)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"invalid die", "application/json", `{"diceNotation":"1d3"}`, http.StatusBadRequest},
+		{"too many dice", "application/json", `{"diceNotation":"1000000000d6"}`, http.StatusBadRequest},
+		{"not JSON", "text/plain", `{"diceNotation":"1d6"}`, http.StatusUnsupportedMediaType},
+		{"form post", "application/x-www-form-urlencoded", `diceNotation=1d6`, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(ts.URL+"/roll", tt.contentType, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestServer_EventsOnNotify(t *testing.T) {
+	s, ts, dir := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected event stream, got %s", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	// Wait for the connection comment so the client is registered
+	if line, err := reader.ReadString('\n'); err != nil || !strings.HasPrefix(line, ":") {
+		t.Fatalf("Expected connection comment, got %q (%v)", line, err)
+	}
+
+	s.Notify([]string{filepath.Join(dir, "monsters", "goblin.md")})
+
+	lines := make(chan string)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- strings.TrimSpace(line)
+		}
+	}()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if line == "data: monsters/goblin.md" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for change event")
+		}
+	}
+}
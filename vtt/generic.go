@@ -0,0 +1,117 @@
+package vtt
+
+import (
+	"character-tool/converter"
+	"character-tool/export"
+	"character-tool/parser"
+	"encoding/json"
+	"fmt"
+)
+
+// GenericSchemaVersion is the version of the generic statblock schema
+const GenericSchemaVersion = 1
+
+// Generic exports a simple statblock JSON for trackers and VTT extensions,
+// such as Owlbear Rodeo's, that have no format of their own
+type Generic struct{}
+
+// GenericStatBlock is the top level of the generic schema
+type GenericStatBlock struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Name          string           `json:"name"`
+	Traits        []GenericAbility `json:"traits"`
+	Actions       []GenericAbility `json:"actions"`
+	BonusActions  []GenericAbility `json:"bonusActions"`
+	Reactions     []GenericAbility `json:"reactions"`
+}
+
+// GenericAbility is one ability. Name is empty for plain text paragraphs.
+type GenericAbility struct {
+	Name  string        `json:"name"`
+	Text  string        `json:"text"`
+	Rolls []GenericRoll `json:"rolls"`
+}
+
+// GenericRoll is a dice roll extracted from an ability
+type GenericRoll struct {
+	Type       string `json:"type"`
+	Notation   string `json:"notation"`
+	Average    int    `json:"average"`
+	DamageType string `json:"damageType,omitempty"`
+}
+
+// Name returns "statblock"
+func (Generic) Name() string {
+	return "statblock"
+}
+
+// Extension returns ".statblock.json"
+func (Generic) Extension() string {
+	return ".statblock.json"
+}
+
+// Export builds the generic statblock with stat-block text and extracted rolls
+func (Generic) Export(result *parser.ParseResult, spells map[string]bool, name string) (string, []string, error) {
+	statBlock := GenericStatBlock{
+		SchemaVersion: GenericSchemaVersion,
+		Name:          name,
+		Traits:        []GenericAbility{},
+		Actions:       []GenericAbility{},
+		BonusActions:  []GenericAbility{},
+		Reactions:     []GenericAbility{},
+	}
+
+	abilities := map[parser.AbilityType]*[]GenericAbility{
+		parser.Trait:       &statBlock.Traits,
+		parser.Action:      &statBlock.Actions,
+		parser.BonusAction: &statBlock.BonusActions,
+		parser.Reaction:    &statBlock.Reactions,
+	}
+
+	var allWarnings []string
+
+	for _, section := range result.Sections() {
+		for _, ability := range section.Abilities {
+			text, warnings, err := export.StatBlockText(ability.RollText, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return "", allWarnings, fmt.Errorf("failed to convert %s: %w", section.Type.SectionName(), err)
+			}
+
+			*abilities[section.Type] = append(*abilities[section.Type], GenericAbility{
+				Name:  ability.Title(),
+				Text:  text,
+				Rolls: genericRolls(ability),
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(statBlock, "", "  ")
+	if err != nil {
+		return "", allWarnings, err
+	}
+	return string(data), allWarnings, nil
+}
+
+// genericRolls lists an ability's rollables with damage types attached
+func genericRolls(ability parser.Ability) []GenericRoll {
+	rolls := []GenericRoll{}
+	text := ability.RollText
+	damageTypes := export.DamageTypes(text)
+	damageIndex := 0
+
+	for _, rollable := range converter.ExtractRollables(text, ability.Name) {
+		roll := GenericRoll{
+			Type:     rollable.RollType,
+			Notation: rollable.DiceNotation,
+			Average:  converter.Average(rollable.DiceNotation),
+		}
+		if rollable.RollType == "damage" {
+			roll.DamageType = damageTypes[damageIndex]
+			damageIndex++
+		}
+		rolls = append(rolls, roll)
+	}
+
+	return rolls
+}
@@ -0,0 +1,47 @@
+package vtt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGeneric_Export(t *testing.T) {
+	output, _, err := Generic{}.Export(testResult(), map[string]bool{"shield": true}, "Veteran")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statBlock GenericStatBlock
+	if err := json.Unmarshal([]byte(output), &statBlock); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+
+	if statBlock.SchemaVersion != GenericSchemaVersion || statBlock.Name != "Veteran" {
+		t.Errorf("Unexpected header %d %q", statBlock.SchemaVersion, statBlock.Name)
+	}
+	if len(statBlock.Traits) != 2 || statBlock.Traits[0].Name != "" {
+		t.Errorf("Expected plain text kept as an unnamed trait, got %+v", statBlock.Traits)
+	}
+
+	rolls := statBlock.Actions[0].Rolls
+	expected := []GenericRoll{
+		{Type: "to hit", Notation: "1d20+5", Average: 16},
+		{Type: "damage", Notation: "1d8+3", Average: 8, DamageType: "slashing"},
+		{Type: "damage", Notation: "1d6", Average: 4, DamageType: "fire"},
+	}
+	if len(rolls) != len(expected) {
+		t.Fatalf("Expected %d rolls, got %+v", len(expected), rolls)
+	}
+	for i := range expected {
+		if rolls[i] != expected[i] {
+			t.Errorf("Roll %d: expected %+v, got %+v", i, expected[i], rolls[i])
+		}
+	}
+
+	if heal := statBlock.BonusActions[0].Rolls; len(heal) != 1 || heal[0].Type != "healing" {
+		t.Errorf("Expected a healing roll, got %+v", heal)
+	}
+	if statBlock.Reactions[0].Rolls == nil || len(statBlock.Reactions[0].Rolls) != 0 {
+		t.Errorf("Expected an empty roll list, got %+v", statBlock.Reactions[0].Rolls)
+	}
+}
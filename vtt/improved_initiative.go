@@ -0,0 +1,133 @@
+package vtt
+
+import (
+	"character-tool/export"
+	"character-tool/parser"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ImprovedInitiative exports Improved Initiative StatBlock JSON, which can be
+// pasted into its statblock editor's JSON tab
+type ImprovedInitiative struct{}
+
+// IIStatBlock is Improved Initiative's StatBlock. Only the power lists come
+// from the markdown; the statistics use Improved Initiative's defaults.
+type IIStatBlock struct {
+	Source                string         `json:"Source"`
+	Type                  string         `json:"Type"`
+	HP                    IIValueNotes   `json:"HP"`
+	AC                    IIValueNotes   `json:"AC"`
+	InitiativeModifier    int            `json:"InitiativeModifier"`
+	InitiativeAdvantage   bool           `json:"InitiativeAdvantage"`
+	Speed                 []string       `json:"Speed"`
+	Abilities             map[string]int `json:"Abilities"`
+	DamageVulnerabilities []string       `json:"DamageVulnerabilities"`
+	DamageResistances     []string       `json:"DamageResistances"`
+	DamageImmunities      []string       `json:"DamageImmunities"`
+	ConditionImmunities   []string       `json:"ConditionImmunities"`
+	Saves                 []IINameValue  `json:"Saves"`
+	Skills                []IINameValue  `json:"Skills"`
+	Senses                []string       `json:"Senses"`
+	Languages             []string       `json:"Languages"`
+	Challenge             string         `json:"Challenge"`
+	Traits                []IIPower      `json:"Traits"`
+	Actions               []IIPower      `json:"Actions"`
+	BonusActions          []IIPower      `json:"BonusActions"`
+	Reactions             []IIPower      `json:"Reactions"`
+	LegendaryActions      []IIPower      `json:"LegendaryActions"`
+	MythicActions         []IIPower      `json:"MythicActions"`
+	Description           string         `json:"Description"`
+	Player                string         `json:"Player"`
+	ImageURL              string         `json:"ImageURL"`
+	Name                  string         `json:"Name"`
+}
+
+// IIValueNotes is a number with a note, used for HP and AC
+type IIValueNotes struct {
+	Value int    `json:"Value"`
+	Notes string `json:"Notes"`
+}
+
+// IINameValue is a named modifier such as a save or skill
+type IINameValue struct {
+	Name     string `json:"Name"`
+	Modifier int    `json:"Modifier"`
+}
+
+// IIPower is a trait, action or reaction. Improved Initiative makes dice in
+// the content rollable.
+type IIPower struct {
+	Name    string `json:"Name"`
+	Content string `json:"Content"`
+	Usage   string `json:"Usage"`
+}
+
+// Name returns "improved-initiative"
+func (ImprovedInitiative) Name() string {
+	return "improved-initiative"
+}
+
+// Extension returns ".ii.json"
+func (ImprovedInitiative) Extension() string {
+	return ".ii.json"
+}
+
+// Export builds the StatBlock. Plain text paragraphs become the description.
+func (ImprovedInitiative) Export(result *parser.ParseResult, spells map[string]bool, name string) (string, []string, error) {
+	statBlock := IIStatBlock{
+		HP:                    IIValueNotes{Value: 1},
+		AC:                    IIValueNotes{Value: 10},
+		Speed:                 []string{},
+		Abilities:             map[string]int{"Str": 10, "Dex": 10, "Con": 10, "Int": 10, "Wis": 10, "Cha": 10},
+		DamageVulnerabilities: []string{},
+		DamageResistances:     []string{},
+		DamageImmunities:      []string{},
+		ConditionImmunities:   []string{},
+		Saves:                 []IINameValue{},
+		Skills:                []IINameValue{},
+		Senses:                []string{},
+		Languages:             []string{},
+		Traits:                []IIPower{},
+		Actions:               []IIPower{},
+		BonusActions:          []IIPower{},
+		Reactions:             []IIPower{},
+		LegendaryActions:      []IIPower{},
+		MythicActions:         []IIPower{},
+		Name:                  name,
+	}
+
+	powers := map[parser.AbilityType]*[]IIPower{
+		parser.Trait:       &statBlock.Traits,
+		parser.Action:      &statBlock.Actions,
+		parser.BonusAction: &statBlock.BonusActions,
+		parser.Reaction:    &statBlock.Reactions,
+	}
+
+	var description []string
+	var allWarnings []string
+
+	for _, section := range result.Sections() {
+		for _, ability := range section.Abilities {
+			content, warnings, err := export.StatBlockText(ability.RollText, spells)
+			allWarnings = append(allWarnings, warnings...)
+			if err != nil {
+				return "", allWarnings, fmt.Errorf("failed to convert %s: %w", section.Type.SectionName(), err)
+			}
+
+			if ability.Name == "" {
+				description = append(description, content)
+				continue
+			}
+			*powers[section.Type] = append(*powers[section.Type], IIPower{Name: ability.Title(), Content: content})
+		}
+	}
+	statBlock.Description = strings.Join(description, "\n\n")
+
+	data, err := json.MarshalIndent(statBlock, "", "  ")
+	if err != nil {
+		return "", allWarnings, err
+	}
+	return string(data), allWarnings, nil
+}
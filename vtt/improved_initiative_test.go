@@ -0,0 +1,48 @@
+package vtt
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestImprovedInitiative_Export(t *testing.T) {
+	output, warnings, err := ImprovedInitiative{}.Export(testResult(), map[string]bool{"shield": true}, "Veteran")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	var statBlock IIStatBlock
+	if err := json.Unmarshal([]byte(output), &statBlock); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+
+	if statBlock.Name != "Veteran" {
+		t.Errorf("Expected name Veteran, got %q", statBlock.Name)
+	}
+	if statBlock.Description != "A grizzled veteran." {
+		t.Errorf("Expected plain text as description, got %q", statBlock.Description)
+	}
+	if len(statBlock.Traits) != 1 || statBlock.Traits[0].Content != "Knows Shield." {
+		t.Errorf("Unexpected traits %+v", statBlock.Traits)
+	}
+
+	expectedAction := "Melee Weapon Attack: +5 to hit. Hit: 8 (1d8 + 3) slashing damage plus 4 (1d6) fire damage."
+	if len(statBlock.Actions) != 1 || statBlock.Actions[0].Content != expectedAction {
+		t.Errorf("Expected action content %q, got %+v", expectedAction, statBlock.Actions)
+	}
+	if len(statBlock.BonusActions) != 1 || len(statBlock.Reactions) != 1 {
+		t.Errorf("Expected one bonus action and reaction, got %+v and %+v", statBlock.BonusActions, statBlock.Reactions)
+	}
+
+	// Improved Initiative rejects statblocks missing these
+	var raw map[string]any
+	json.Unmarshal([]byte(output), &raw)
+	for _, key := range []string{"HP", "AC", "Abilities", "Speed", "LegendaryActions", "MythicActions"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("Expected %s in output", key)
+		}
+	}
+}
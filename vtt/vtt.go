@@ -0,0 +1,15 @@
+package vtt
+
+import "character-tool/parser"
+
+// Exporter converts parsed abilities into a statblock file for a virtual
+// tabletop or encounter tracker
+type Exporter interface {
+	// Name is the value passed to --format
+	Name() string
+	// Extension is appended to the input's stem to name the output file
+	Extension() string
+	// Export returns the file contents for a creature called name, plus
+	// conversion warnings
+	Export(result *parser.ParseResult, spells map[string]bool, name string) (string, []string, error)
+}
@@ -0,0 +1,22 @@
+package vtt
+
+import "character-tool/parser"
+
+// testResult is a small character exercising every section and roll type
+func testResult() *parser.ParseResult {
+	return &parser.ParseResult{
+		Traits: []parser.Ability{
+			{Name: "", Description: "A grizzled veteran.", RollText: "A grizzled veteran.", Type: parser.Trait},
+			{Name: "Spellcasting", Description: "Knows {{spell:Shield}}.", RollText: "Knows {{spell:Shield}}.", Type: parser.Trait},
+		},
+		Actions: []parser.Ability{
+			{Name: "Longsword", Description: "Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing damage plus damage: 1d6 fire damage.", RollText: "Melee Weapon Attack: to hit: 1d20+5. Hit: damage: 1d8+3 slashing damage plus damage: 1d6 fire damage.", Type: parser.Action},
+		},
+		BonusActions: []parser.Ability{
+			{Name: "Second Wind", Description: "Regain healing: 1d10+5 hit points.", RollText: "Regain healing: 1d10+5 hit points.", Type: parser.BonusAction},
+		},
+		Reactions: []parser.Ability{
+			{Name: "Parry", Description: "Add 2 to AC.", RollText: "Add 2 to AC.", Type: parser.Reaction},
+		},
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"character-tool/batch"
+	"character-tool/converter"
+	"character-tool/watcher"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	debounce     time.Duration
+	pollMode     bool
+	pollInterval time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [files, directories or globs...]",
+	Short: "Regenerate output files whenever input markdown changes",
+	Long: `Builds every input once, then watches the inputs and regenerates the output
+files of each character as soon as its markdown is saved.
+
+Filesystem notifications are used where available; --poll switches to
+periodically scanning the inputs instead (useful on network drives and
+synced folders). Warnings are printed after every rebuild.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputs, err := collectInputs(args)
+		if err != nil {
+			return err
+		}
+		opts, err := outputFlags()
+		if err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+		return runWatch(inputs, outputDir, vaultMode, workers, opts, watcher.Options{
+			Debounce:     debounce,
+			Poll:         pollMode,
+			PollInterval: pollInterval,
+		})
+	},
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&keepMd, "keep-markdown", false, keepMarkdownUsage)
+	watchCmd.Flags().DurationVar(&debounce, "debounce", watcher.DefaultDebounce, "wait this long after the last save before rebuilding")
+	watchCmd.Flags().BoolVar(&pollMode, "poll", false, "poll for changes instead of using filesystem notifications")
+	watchCmd.Flags().DurationVar(&pollInterval, "poll-interval", watcher.DefaultPollInterval, "how often to scan for changes in poll mode")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(inputs []string, outputDir string, vaultMode bool, workers int, output outputOptions, opts watcher.Options) error {
+	spells, err := converter.LoadSpells()
+	if err != nil {
+		return fmt.Errorf("failed to load spell list: %w", err)
+	}
+
+	// rebuild regenerates the changed files, or every input when changed is nil
+	rebuild := func(changed []string) {
+		files, outputDirs, err := resolveInputs(inputs, outputDir, vaultMode, output.renderer)
+		if err != nil {
+			fmt.Printf("%s ✗ %v\n", timestamp(), err)
+			return
+		}
+		if changed != nil {
+			files = slices.DeleteFunc(slices.Clone(changed), func(path string) bool {
+				_, ok := outputDirs[path]
+				return !ok
+			})
+		}
+
+		results := batch.Run(files, workers, func(input string) batch.Result {
+			return processFile(input, outputDirs[input], spells, output)
+		})
+		printRebuild(results)
+	}
+
+	rebuild(nil)
+
+	w, err := watcher.New(watchRoots(inputs), opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("\nWatching for changes (%s). Press Ctrl+C to stop.\n", w.Mode())
+	return w.Run(ctx, rebuild)
+}
+
+// watchRoots expands glob patterns so the watcher sees concrete files and directories
+func watchRoots(inputs []string) []string {
+	var roots []string
+	for _, input := range inputs {
+		matches, err := filepath.Glob(input)
+		if err != nil || len(matches) == 0 {
+			roots = append(roots, input)
+			continue
+		}
+		roots = append(roots, matches...)
+	}
+	return roots
+}
+
+// printRebuild reports each regenerated file along with its warnings
+func printRebuild(results []batch.Result) {
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("%s ✗ %s: %v\n", timestamp(), result.Input, result.Err)
+			continue
+		}
+
+		fmt.Printf("%s ✓ %s → %s (%d abilities, %d warnings)\n",
+			timestamp(), result.Input, result.OutputDir, result.Abilities, len(result.Warnings))
+		for _, warning := range result.Warnings {
+			fmt.Printf("    ! %s\n", warning)
+		}
+	}
+}
+
+// timestamp returns the current time formatted for rebuild log lines
+func timestamp() string {
+	return time.Now().Format("15:04:05")
+}
@@ -0,0 +1,299 @@
+package watcher
+
+import (
+	"character-tool/batch"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Default timings used when Options leaves them unset
+const (
+	DefaultDebounce     = 300 * time.Millisecond
+	DefaultPollInterval = time.Second
+)
+
+// Options configures how inputs are watched
+type Options struct {
+	// Debounce is how long the watcher waits after the last change before
+	// reporting a batch, so that editors saving in several steps trigger one rebuild
+	Debounce time.Duration
+	// Poll forces the polling backend instead of filesystem notifications
+	Poll bool
+	// PollInterval is how often the polling backend rescans the inputs
+	PollInterval time.Duration
+}
+
+// Watcher reports batches of changed markdown files under a set of roots
+type Watcher struct {
+	roots    []string
+	opts     Options
+	notifier *fsnotify.Watcher
+}
+
+// New creates a watcher for the given files and directories. Filesystem
+// notifications are used when available, falling back to polling otherwise.
+func New(roots []string, opts Options) (*Watcher, error) {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	for _, root := range roots {
+		if _, err := os.Stat(root); err != nil {
+			return nil, fmt.Errorf("failed to watch %q: %w", root, err)
+		}
+	}
+
+	w := &Watcher{roots: roots, opts: opts}
+	if opts.Poll {
+		return w, nil
+	}
+
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		// Notifications unavailable (e.g. inotify limits reached): poll instead
+		return w, nil
+	}
+	if err := w.addWatches(notifier); err != nil {
+		notifier.Close()
+		return nil, err
+	}
+	w.notifier = notifier
+
+	return w, nil
+}
+
+// Mode returns the backend in use: "fsnotify" or "poll"
+func (w *Watcher) Mode() string {
+	if w.notifier != nil {
+		return "fsnotify"
+	}
+	return "poll"
+}
+
+// Run blocks until ctx is cancelled, calling onChange with the sorted list of
+// markdown files that changed during each debounce window
+func (w *Watcher) Run(ctx context.Context, onChange func(changed []string)) error {
+	events := make(chan string)
+	errs := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if w.notifier != nil {
+		go w.notify(ctx, w.notifier, events, errs)
+	} else {
+		go w.poll(ctx, events)
+	}
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(w.opts.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case path := <-events:
+			pending[path] = true
+			timer.Reset(w.opts.Debounce)
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			changed := make([]string, 0, len(pending))
+			for path := range pending {
+				changed = append(changed, path)
+			}
+			sort.Strings(changed)
+			clear(pending)
+			onChange(changed)
+		}
+	}
+}
+
+// addWatches registers every root with the notifier. Directories are watched
+// recursively; single files are watched through their parent directory so
+// that editors which save by renaming a temporary file are still noticed.
+func (w *Watcher) addWatches(notifier *fsnotify.Watcher) error {
+	for _, root := range w.roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return fmt.Errorf("failed to watch %q: %w", root, err)
+		}
+
+		if !info.IsDir() {
+			if err := notifier.Add(filepath.Dir(root)); err != nil {
+				return fmt.Errorf("failed to watch %q: %w", root, err)
+			}
+			continue
+		}
+
+		if err := addTree(notifier, root); err != nil {
+			return fmt.Errorf("failed to watch %q: %w", root, err)
+		}
+	}
+	return nil
+}
+
+// addTree adds a directory and all of its subdirectories to the notifier
+func addTree(notifier *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return notifier.Add(path)
+		}
+		return nil
+	})
+}
+
+// notify forwards relevant filesystem notifications as changed paths
+func (w *Watcher) notify(ctx context.Context, notifier *fsnotify.Watcher, events chan<- string, errs chan<- error) {
+	defer notifier.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-notifier.Errors:
+			if !ok {
+				return
+			}
+			errs <- fmt.Errorf("watch error: %w", err)
+			return
+		case event, ok := <-notifier.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+
+			// Newly created directories inside a watched tree need their own watch
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && w.inWatchedDir(event.Name) {
+					addTree(notifier, event.Name)
+					continue
+				}
+			}
+
+			if w.relevant(event.Name) {
+				select {
+				case events <- filepath.Clean(event.Name):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// poll rescans the roots on a fixed interval and reports files whose
+// modification time or size changed since the previous scan
+func (w *Watcher) poll(ctx context.Context, events chan<- string) {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	previous := w.snapshot()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := w.snapshot()
+			for path, state := range current {
+				if old, ok := previous[path]; ok && old == state {
+					continue
+				}
+				select {
+				case events <- path:
+				case <-ctx.Done():
+					return
+				}
+			}
+			previous = current
+		}
+	}
+}
+
+// fileState is the part of a file's metadata used to detect changes when polling
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot records the state of every watched markdown file
+func (w *Watcher) snapshot() map[string]fileState {
+	states := make(map[string]fileState)
+	record := func(path string, info fs.FileInfo) {
+		states[filepath.Clean(path)] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	for _, root := range w.roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			record(root, info)
+			continue
+		}
+
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !batch.IsMarkdown(path) {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				record(path, info)
+			}
+			return nil
+		})
+	}
+
+	return states
+}
+
+// relevant reports whether a changed path is one of the watched inputs
+func (w *Watcher) relevant(path string) bool {
+	path = filepath.Clean(path)
+	for _, root := range w.roots {
+		root = filepath.Clean(root)
+		if path == root {
+			return true
+		}
+	}
+	return batch.IsMarkdown(path) && w.inWatchedDir(path)
+}
+
+// inWatchedDir reports whether path lies inside one of the directory roots
+func (w *Watcher) inWatchedDir(path string) bool {
+	for _, root := range w.roots {
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !filepath.IsAbs(rel) && !startsWithParent(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// startsWithParent reports whether a relative path escapes its base directory
+func startsWithParent(rel string) bool {
+	return len(rel) >= 3 && rel[:3] == ".."+string(filepath.Separator)
+}
@@ -0,0 +1,136 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// collect runs the watcher in the background and returns a channel of change batches
+func collect(t *testing.T, w *Watcher) <-chan []string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	batches := make(chan []string, 10)
+	go w.Run(ctx, func(changed []string) {
+		batches <- changed
+	})
+	return batches
+}
+
+// expectBatch waits for the next change batch and compares it with expected
+func expectBatch(t *testing.T, batches <-chan []string, expected []string) {
+	t.Helper()
+	select {
+	case changed := <-batches:
+		if !reflect.DeepEqual(changed, expected) {
+			t.Errorf("Expected %v, got %v", expected, changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %v", expected)
+	}
+}
+
+// expectNoBatch fails if a change batch arrives within the wait period
+func expectNoBatch(t *testing.T, batches <-chan []string, wait time.Duration) {
+	t.Helper()
+	select {
+	case changed := <-batches:
+		t.Errorf("Expected no changes, got %v", changed)
+	case <-time.After(wait):
+	}
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcher_NotifyDebouncesDirectoryChanges(t *testing.T) {
+	dir := t.TempDir()
+	fighter := filepath.Join(dir, "fighter.md")
+	write(t, fighter, "## Traits\n")
+
+	w, err := New([]string{dir}, Options{Debounce: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if w.Mode() != "fsnotify" {
+		t.Skipf("filesystem notifications unavailable, got mode %s", w.Mode())
+	}
+	batches := collect(t, w)
+
+	// Several saves in quick succession produce a single batch
+	write(t, fighter, "## Traits\n\n**A.** One.\n")
+	write(t, fighter, "## Traits\n\n**A.** Two.\n")
+	write(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	expectBatch(t, batches, []string{fighter})
+	expectNoBatch(t, batches, 200*time.Millisecond)
+}
+
+func TestWatcher_NotifyNewSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := New([]string{dir}, Options{Debounce: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if w.Mode() != "fsnotify" {
+		t.Skipf("filesystem notifications unavailable, got mode %s", w.Mode())
+	}
+	batches := collect(t, w)
+
+	sub := filepath.Join(dir, "monsters")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Give the watcher a moment to register the new directory
+	time.Sleep(100 * time.Millisecond)
+
+	goblin := filepath.Join(sub, "goblin.md")
+	write(t, goblin, "## Actions\n")
+
+	expectBatch(t, batches, []string{goblin})
+}
+
+func TestWatcher_PollSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	fighter := filepath.Join(dir, "fighter.md")
+	other := filepath.Join(dir, "wizard.md")
+	write(t, fighter, "## Traits\n")
+	write(t, other, "## Traits\n")
+
+	w, err := New([]string{fighter}, Options{
+		Debounce:     20 * time.Millisecond,
+		Poll:         true,
+		PollInterval: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if w.Mode() != "poll" {
+		t.Fatalf("Expected poll mode, got %s", w.Mode())
+	}
+	batches := collect(t, w)
+
+	// Wait for the initial snapshot before changing anything
+	time.Sleep(50 * time.Millisecond)
+	write(t, other, "## Traits\n\n**Unwatched.** Change.\n")
+	write(t, fighter, "## Traits\n\n**Watched.** Change.\n")
+
+	expectBatch(t, batches, []string{fighter})
+}
+
+func TestNew_MissingRoot(t *testing.T) {
+	_, err := New([]string{filepath.Join(t.TempDir(), "missing")}, Options{})
+	if err == nil {
+		t.Error("Expected error for missing root, got nil")
+	}
+}